@@ -13,8 +13,7 @@ import (
 )
 
 func main() {
-	prog := ttyprogress.For(os.Stdout)
-	ext := progress.New(prog)
+	ext := progress.NewFor(os.Stdout)
 
 	sched := scheduler.New("demo1")
 
@@ -53,9 +52,7 @@ func main() {
 	job1.Wait()
 	job2.Wait()
 	job3.Wait()
-	prog.Close()
-	prog.Wait(nil)
-
+	sched.Shutdown(context.Background())
 }
 
 func runner1(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
@@ -72,15 +69,12 @@ func runner1(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
 func runner2(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
 	job := ctx.Job()
 
-	ext := progress.GetExtension(job)
-
-	bar := ext.GetIndicator().(ttyprogress.Bar)
 	lines := rand.Intn(10) + 10
-	bar.SetTotal(lines)
+	ctx.Progress().SetTotal(lines)
 	for i := 0; i < lines; i++ {
 		time.Sleep(time.Duration((500 + rand.Intn(100))) * time.Millisecond)
 		fmt.Fprintf(ctx, "job %s line %d\n", job.GetId(), i+1)
-		bar.Set(i + 1)
+		ctx.Progress().Set(i + 1)
 	}
 	return nil, nil
 }
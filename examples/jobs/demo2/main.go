@@ -6,12 +6,12 @@ import (
 	"math/rand"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/mandelsoft/goutils/general"
-	"github.com/mandelsoft/jobscheduler/processors"
 	"github.com/mandelsoft/jobscheduler/scheduler"
 	"github.com/mandelsoft/jobscheduler/scheduler/extensions/buffered"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/multi"
 	"github.com/mandelsoft/jobscheduler/scheduler/extensions/progress"
 	"github.com/mandelsoft/jobscheduler/scheduler/extensions/writer"
 	"github.com/mandelsoft/ttyprogress"
@@ -83,57 +83,41 @@ func main() {
 	job.Schedule()
 
 	job.Wait()
-	if useVis != nil {
-		useVis.Close()
-	}
+	sched.Shutdown(context.Background())
 }
 
 type DataProcessor struct {
 	data *Data
-	wait *processors.WaitGroup
 }
 
-func NewDataProcessor(data *Data, wait ...*processors.WaitGroup) *DataProcessor {
-	return &DataProcessor{data, general.Optional(wait...)}
+func NewDataProcessor(data *Data) *DataProcessor {
+	return &DataProcessor{data}
 }
 
 func (p *DataProcessor) Run(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
 	job := ctx.Job()
 
 	// schedule nested jobs
-	nested := processors.NewWaitGroup()
+	nested := scheduler.NewGroup(ctx)
 	for n, s := range p.data.Nested {
-		nested.Add(1)
-		proc := NewDataProcessor(s, nested)
-		job, err := ctx.Scheduler().Apply(jobDef.SetName(n).SetRunner(proc), job)
-		if err != nil {
-			return nil, err
-		}
-		err = job.Schedule()
+		_, err := nested.GoDefinition(jobDef.SetName(n).SetRunner(NewDataProcessor(s)))
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// do local work
-	var bar ttyprogress.Bar
-
-	ext := progress.GetExtension(job)
-	if ext != nil {
-		bar = ext.GetIndicator().(ttyprogress.Bar)
-		bar.SetTotal(p.data.Steps + 1)
-	}
+	prog := ctx.Progress()
+	prog.SetTotal(p.data.Steps + 1)
 
 	for i := 0; i < p.data.Steps; i++ {
 		time.Sleep(time.Duration((500 + rand.Intn(100))) * time.Millisecond)
 		fmt.Fprintf(ctx, "job %s line %d\n", job.GetId(), i+1)
-		if bar != nil {
-			bar.Set(i + 1)
-		}
+		prog.Set(i + 1)
 	}
 
 	fmt.Fprintf(ctx, "job %s waiting for nested\n", job.GetId())
-	err := nested.Wait(ctx)
+	err := nested.Wait()
 	if err != nil {
 		return nil, err
 	}
@@ -141,12 +125,7 @@ func (p *DataProcessor) Run(ctx scheduler.SchedulingContext) (scheduler.Result,
 	fmt.Fprintf(ctx, "job %s gathering nested\n", job.GetId())
 
 	// processing finished
-	if p.wait != nil {
-		p.wait.Done()
-	}
-	if bar != nil {
-		bar.Set(p.data.Steps + 1)
-	}
+	prog.Complete()
 	return nil, nil
 }
 
@@ -190,10 +169,20 @@ func options(args ...string) (int, scheduler.Extension) {
 }
 
 func extension(name string) (scheduler.Extension, error) {
+	if names := strings.Split(name, ","); len(names) > 1 {
+		var members []scheduler.Extension
+		for _, n := range names {
+			e, err := extension(n)
+			if err != nil {
+				return nil, err
+			}
+			members = append(members, e)
+		}
+		return multi.New(members...), nil
+	}
 	switch name {
 	case "progress":
-		ctx := ttyprogress.For(os.Stdout)
-		return progress.New(ctx), nil
+		return progress.NewFor(os.Stdout), nil
 	case "buffered":
 		return buffered.New(os.Stdout), nil
 	case "writer":
@@ -12,12 +12,10 @@ import (
 	"github.com/mandelsoft/jobscheduler/scheduler/extensions/buffered"
 	"github.com/mandelsoft/jobscheduler/scheduler/extensions/progress"
 	"github.com/mandelsoft/jobscheduler/scheduler/jobnet"
-	"github.com/mandelsoft/ttyprogress"
 )
 
 func main() {
-	prog := ttyprogress.For(os.Stdout)
-	ext := progress.New(prog)
+	ext := progress.NewFor(os.Stdout)
 	_ = ext
 
 	sched := scheduler.New("jobnet")
@@ -0,0 +1,44 @@
+package processors
+
+import (
+	"github.com/mandelsoft/jobscheduler/syncutils"
+)
+
+type Barrier interface {
+	syncutils.Barrier
+}
+
+// NewBarrier creates a new cyclic Barrier for n parties
+// working on a Pool. The pool must be bound to the context.Context.
+// A Go routine waiting at the barrier releases its
+// pool capacity while blocked.
+func NewBarrier(n int) Barrier {
+	h := &limithandler{}
+	return bind(h, syncutils.NewBarrier(n, h))
+}
+
+type CountDownLatch interface {
+	syncutils.CountDownLatch
+}
+
+// NewCountDownLatch creates a new CountDownLatch with the count n
+// working on a Pool. The pool must be bound to the context.Context.
+// A Go routine waiting for the latch releases its
+// pool capacity while blocked.
+func NewCountDownLatch(n int) CountDownLatch {
+	h := &limithandler{}
+	return bind(h, syncutils.NewCountDownLatch(n, h))
+}
+
+type Phaser interface {
+	syncutils.Phaser
+}
+
+// NewPhaser creates a new Phaser with n initially registered parties
+// working on a Pool. The pool must be bound to the context.Context.
+// A Go routine waiting for a phase advance releases its
+// pool capacity while blocked.
+func NewPhaser(n int) Phaser {
+	h := &limithandler{}
+	return bind(h, syncutils.NewPhaser(n, h))
+}
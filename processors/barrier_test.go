@@ -0,0 +1,59 @@
+package processors_test
+
+import (
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+	"github.com/mandelsoft/jobscheduler/syncutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Barrier Test Environment", func() {
+	var p processors.Pool
+
+	BeforeEach(func() {
+		p = processors.NewDefaultPool()
+	})
+
+	It("waits at barrier", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		b := processors.NewBarrier(3)
+		wg := syncutils.NewWaitGroup()
+		wg.Add(2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				defer GinkgoRecover()
+				MustBeSuccessful(b.Wait(ctx))
+				MustBeSuccessful(b.Wait(ctx))
+				wg.Done()
+			}()
+		}
+		time.Sleep(100 * time.Millisecond)
+		MustBeSuccessful(b.Wait(ctx))
+		MustBeSuccessful(b.Wait(ctx))
+		MustBeSuccessful(wg.Wait(ctx))
+	}, SpecTimeout(2*time.Second))
+
+	It("waits for latch", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		l := processors.NewCountDownLatch(1)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			l.CountDown()
+		}()
+		MustBeSuccessful(l.Wait(ctx))
+	}, SpecTimeout(2*time.Second))
+
+	It("waits for phase", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		ph := processors.NewPhaser(2)
+		go func() {
+			defer GinkgoRecover()
+			time.Sleep(100 * time.Millisecond)
+			Expect(ph.ArriveAndAwaitAdvance(ctx)).To(Equal(1))
+		}()
+		Expect(ph.ArriveAndAwaitAdvance(ctx)).To(Equal(1))
+	}, SpecTimeout(2*time.Second))
+})
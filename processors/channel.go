@@ -27,6 +27,13 @@ func (c Channel[T]) Close() error {
 }
 
 func ReceiveFromChannel[T any](ctx context.Context, c <-chan T) (T, bool, error) {
+	return ReceiveFromChannelFor(ctx, c, c)
+}
+
+// ReceiveFromChannelFor works like ReceiveFromChannel, but passes
+// the given object as the object waited for to the pool,
+// for example a SelfTerminating description of the operation.
+func ReceiveFromChannelFor[T any](ctx context.Context, c <-chan T, obj any) (T, bool, error) {
 	var _nil T
 
 	pool := GetPool(ctx)
@@ -40,7 +47,7 @@ func ReceiveFromChannel[T any](ctx context.Context, c <-chan T) (T, bool, error)
 		return v, ok, nil
 	default:
 	}
-	pool.Release(ctx)
+	release(ctx, pool, obj)
 	select {
 	case v, ok := <-c:
 		err := pool.Alloc(ctx)
@@ -63,7 +70,7 @@ func SendToChannel[T any](ctx context.Context, c chan<- T, v T) error {
 		return nil
 	default:
 	}
-	pool.Release(ctx)
+	release(ctx, pool, c)
 	select {
 	case c <- v:
 		return pool.Alloc(ctx)
@@ -0,0 +1,24 @@
+package processors
+
+import (
+	"sync"
+
+	"github.com/mandelsoft/jobscheduler/syncutils"
+)
+
+type Cond interface {
+	syncutils.Cond
+}
+
+// NewCond creates a new Cond working on
+// a Pool for the given lock. The pool must be bound
+// to the context.Context.
+// A Go routine waiting for the condition releases its
+// pool capacity while blocked.
+// Several Conds may share the same lock to provide multiple
+// wait queues, for example for readers and writers
+// of a bounded buffer.
+func NewCond(l sync.Locker) Cond {
+	h := &limithandler{}
+	return bind(h, syncutils.NewCond(l, h))
+}
@@ -0,0 +1,65 @@
+package processors_test
+
+import (
+	"sync"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+	"github.com/mandelsoft/jobscheduler/syncutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cond Test Environment", func() {
+	var p processors.Pool
+
+	BeforeEach(func() {
+		p = processors.NewDefaultPool()
+	})
+
+	It("waits for condition", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		var lock sync.Mutex
+		cond := processors.NewCond(&lock)
+
+		ready := false
+		wg := syncutils.NewWaitGroup()
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			lock.Lock()
+			for !ready {
+				MustBeSuccessful(cond.Wait(ctx))
+			}
+			lock.Unlock()
+			wg.Done()
+		}()
+		time.Sleep(100 * time.Millisecond)
+		lock.Lock()
+		ready = true
+		Expect(cond.Broadcast()).To(BeTrue())
+		lock.Unlock()
+		MustBeSuccessful(wg.Wait(ctx))
+	}, SpecTimeout(2*time.Second))
+
+	It("waits for rwmutex", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		m := processors.NewRWMutex()
+
+		MustBeSuccessful(m.RLock(ctx))
+		wg := syncutils.NewWaitGroup()
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			MustBeSuccessful(m.Lock(ctx))
+			m.Unlock()
+			wg.Done()
+		}()
+		time.Sleep(100 * time.Millisecond)
+		Expect(m.TryLock()).To(BeFalse())
+		m.RUnlock()
+		MustBeSuccessful(wg.Wait(ctx))
+		Expect(m.TryLock()).To(BeTrue())
+	}, SpecTimeout(2*time.Second))
+})
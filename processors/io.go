@@ -0,0 +1,339 @@
+package processors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// ReadDeadliner is an optional interface of an io.Reader
+// supporting a deadline for blocking read operations,
+// like an os.File for pipes or a net.Conn.
+type ReadDeadliner interface {
+	SetReadDeadline(t time.Time) error
+}
+
+// WriteDeadliner is an optional interface of an io.Writer
+// supporting a deadline for blocking write operations,
+// like an os.File for pipes or a net.Conn.
+type WriteDeadliner interface {
+	SetWriteDeadline(t time.Time) error
+}
+
+// aLongTimeAgo is used as deadline to interrupt
+// blocking operations.
+var aLongTimeAgo = time.Unix(1, 0)
+
+// probeTimeout is the time an I/O operation may block before
+// the pool is released. Operations completing within this time,
+// for example because data is already available, are executed
+// without releasing the pool.
+var probeTimeout = time.Millisecond
+
+// Reader wraps an io.Reader to release the pool
+// bound to the context.Context around blocking read operations.
+// Reads completing immediately keep the pool.
+// If the reader supports a read deadline (ReadDeadliner),
+// a short deadline is used to probe whether a read would block
+// and a blocking read is interrupted when the context is cancelled
+// by setting a deadline in the past. Deadlines must then be set
+// by the SetReadDeadline method of the returned reader to be
+// restored after probing or interrupting a read.
+// Otherwise, the read is executed by a helper Go routine and
+// the cancellation is only checked before a read operation.
+func Reader(ctx context.Context, r io.Reader) io.Reader {
+	if GetPool(ctx) == nil {
+		return r
+	}
+	if d, ok := r.(ReadDeadliner); ok {
+		return &deadlineReader{reader{ctx: ctx, reader: r, deadline: newIODeadline(d.SetReadDeadline)}}
+	}
+	return &reader{ctx: ctx, reader: r}
+}
+
+// Writer wraps an io.Writer to release the pool
+// bound to the context.Context around blocking write operations.
+// Writes completing immediately keep the pool.
+// If the writer supports a write deadline (WriteDeadliner),
+// a short deadline is used to probe whether a write would block
+// and a blocking write is interrupted when the context is cancelled
+// by setting a deadline in the past. Deadlines must then be set
+// by the SetWriteDeadline method of the returned writer to be
+// restored after probing or interrupting a write.
+// Otherwise, the write is executed by a helper Go routine and
+// the cancellation is only checked before a write operation.
+func Writer(ctx context.Context, w io.Writer) io.Writer {
+	if GetPool(ctx) == nil {
+		return w
+	}
+	if d, ok := w.(WriteDeadliner); ok {
+		return &deadlineWriter{writer{ctx: ctx, writer: w, deadline: newIODeadline(d.SetWriteDeadline)}}
+	}
+	return &writer{ctx: ctx, writer: w}
+}
+
+// Conn wraps a net.Conn to release the pool bound to
+// the context.Context around blocking read and write operations.
+// Blocking operations are interrupted when the context is cancelled.
+// Deadlines must be set by the returned connection to be
+// restored after probing or interrupting an operation.
+func Conn(ctx context.Context, c net.Conn) net.Conn {
+	if GetPool(ctx) == nil {
+		return c
+	}
+	return &conn{
+		Conn:  c,
+		ctx:   ctx,
+		read:  newIODeadline(c.SetReadDeadline),
+		write: newIODeadline(c.SetWriteDeadline),
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// ioDeadline keeps track of the deadline set by the caller
+// to restore it after it has been modified to probe or
+// interrupt an operation.
+type ioDeadline struct {
+	lock     sync.Mutex
+	set      func(time.Time) error
+	deadline time.Time
+}
+
+func newIODeadline(set func(time.Time) error) *ioDeadline {
+	return &ioDeadline{set: set}
+}
+
+// Set sets the deadline requested by the caller.
+func (d *ioDeadline) Set(t time.Time) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.deadline = t
+	return d.set(t)
+}
+
+// IsSet reports whether the caller has set a deadline.
+func (d *ioDeadline) IsSet() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return !d.deadline.IsZero()
+}
+
+// IsExpired reports whether the deadline set by the
+// caller has been reached.
+func (d *ioDeadline) IsExpired() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return !d.deadline.IsZero() && !time.Now().Before(d.deadline)
+}
+
+// probe sets a short deadline to check whether an
+// operation would block. It returns false, if the object
+// does not support deadlines, like a regular file.
+func (d *ioDeadline) probe() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	t := time.Now().Add(probeTimeout)
+	if !d.deadline.IsZero() && d.deadline.Before(t) {
+		t = d.deadline
+	}
+	return d.set(t) == nil
+}
+
+// interrupt interrupts a blocking operation.
+func (d *ioDeadline) interrupt() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.set(aLongTimeAgo)
+}
+
+// restore restores the deadline set by the caller.
+func (d *ioDeadline) restore() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	d.set(d.deadline)
+}
+
+// ioWaiting describes a blocking I/O operation.
+type ioWaiting struct {
+	op       string
+	obj      any
+	deadline *ioDeadline
+}
+
+func (w *ioWaiting) String() string {
+	return fmt.Sprintf("%s %T", w.op, w.obj)
+}
+
+// IsSelfTerminating reports operations ending on their own:
+// operations with a deadline and operations on network
+// connections, whose peer is outside the scheduler.
+// Other objects, like a pipe, might be served by another job.
+func (w *ioWaiting) IsSelfTerminating() bool {
+	if _, ok := w.obj.(net.Conn); ok {
+		return true
+	}
+	return w.deadline != nil && w.deadline.IsSet()
+}
+
+// blockingIO executes an I/O operation. If it would block,
+// it is executed with released pool capacity. If a deadline
+// is given, the operation is interrupted when the context is
+// cancelled. A partially executed write is continued
+// with the rest of the data.
+func blockingIO(ctx context.Context, desc *ioWaiting, p []byte, write bool, op func([]byte) (int, error)) (int, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	d := desc.deadline
+	if d == nil || !d.probe() {
+		return helperIO(ctx, desc, p, op)
+	}
+	n, err := op(p)
+	d.restore()
+	if !errors.Is(err, os.ErrDeadlineExceeded) || d.IsExpired() || (n > 0 && !write) {
+		if n > 0 && !write {
+			err = nil
+		}
+		return n, err
+	}
+
+	// the operation would block.
+	pool := GetPool(ctx)
+	release(ctx, pool, desc)
+
+	interrupted := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		d.interrupt()
+		close(interrupted)
+	})
+	m, err := op(p[n:])
+	n += m
+	if !stop() {
+		// the operation has been interrupted. The deadline is restored
+		// to keep the underlying object usable for further operations.
+		<-interrupted
+		d.restore()
+		if err != nil {
+			err = ctx.Err()
+		}
+	}
+
+	err2 := pool.Alloc(ctx)
+	if err == nil {
+		err = err2
+	}
+	return n, err
+}
+
+// helperIO executes an I/O operation without deadline support
+// by a helper Go routine. If it does not complete within the
+// probe timeout, the pool is released until it completes.
+func helperIO(ctx context.Context, desc *ioWaiting, p []byte, op func([]byte) (int, error)) (int, error) {
+	type result struct {
+		n   int
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		n, err := op(p)
+		done <- result{n, err}
+	}()
+
+	timer := time.NewTimer(probeTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-timer.C:
+	}
+
+	pool := GetPool(ctx)
+	release(ctx, pool, desc)
+	r := <-done
+	err := pool.Alloc(ctx)
+	if r.err == nil {
+		r.err = err
+	}
+	return r.n, r.err
+}
+
+type reader struct {
+	ctx      context.Context
+	reader   io.Reader
+	deadline *ioDeadline
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	return blockingIO(r.ctx, &ioWaiting{"read from", r.reader, r.deadline}, p, false, r.reader.Read)
+}
+
+type deadlineReader struct {
+	reader
+}
+
+var _ ReadDeadliner = (*deadlineReader)(nil)
+
+func (r *deadlineReader) SetReadDeadline(t time.Time) error {
+	return r.deadline.Set(t)
+}
+
+type writer struct {
+	ctx      context.Context
+	writer   io.Writer
+	deadline *ioDeadline
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	return blockingIO(w.ctx, &ioWaiting{"write to", w.writer, w.deadline}, p, true, w.writer.Write)
+}
+
+type deadlineWriter struct {
+	writer
+}
+
+var _ WriteDeadliner = (*deadlineWriter)(nil)
+
+func (w *deadlineWriter) SetWriteDeadline(t time.Time) error {
+	return w.deadline.Set(t)
+}
+
+type conn struct {
+	net.Conn
+	ctx   context.Context
+	read  *ioDeadline
+	write *ioDeadline
+}
+
+func (c *conn) Read(p []byte) (int, error) {
+	return blockingIO(c.ctx, &ioWaiting{"read from", c.Conn, c.read}, p, false, c.Conn.Read)
+}
+
+func (c *conn) Write(p []byte) (int, error) {
+	return blockingIO(c.ctx, &ioWaiting{"write to", c.Conn, c.write}, p, true, c.Conn.Write)
+}
+
+func (c *conn) SetDeadline(t time.Time) error {
+	return errors.Join(c.read.Set(t), c.write.Set(t))
+}
+
+func (c *conn) SetReadDeadline(t time.Time) error {
+	return c.read.Set(t)
+}
+
+func (c *conn) SetWriteDeadline(t time.Time) error {
+	return c.write.Set(t)
+}
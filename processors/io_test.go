@@ -0,0 +1,220 @@
+package processors_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// recordingPool records whether the objects waited for
+// are self-terminating.
+type recordingPool struct {
+	countingPool
+	selfTerminating []bool
+}
+
+var _ processors.BlockingPool = (*recordingPool)(nil)
+
+func (p *recordingPool) ReleaseFor(ctx context.Context, obj any) {
+	s, ok := obj.(processors.SelfTerminating)
+	p.selfTerminating = append(p.selfTerminating, ok && s.IsSelfTerminating())
+	p.Release(ctx)
+}
+
+var _ = Describe("IO Test Environment", func() {
+	var p *countingPool
+
+	BeforeEach(func() {
+		p = &countingPool{}
+	})
+
+	It("keeps reader without pool", func(ctx SpecContext) {
+		r, w := Must2(os.Pipe())
+		defer r.Close()
+		defer w.Close()
+		Expect(processors.Reader(ctx, r)).To(BeIdenticalTo(r))
+	})
+
+	It("reads from pipe", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		r, w := Must2(os.Pipe())
+		defer r.Close()
+		defer w.Close()
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("data"))
+		}()
+		buf := make([]byte, 10)
+		n := Must(processors.Reader(ctx, r).Read(buf))
+		Expect(string(buf[:n])).To(Equal("data"))
+		Expect(p.releases.Load()).To(Equal(int32(1)))
+		Expect(p.allocs.Load()).To(Equal(int32(1)))
+	}, SpecTimeout(2*time.Second))
+
+	It("reads available data without releasing the pool", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		r, w := Must2(os.Pipe())
+		defer r.Close()
+		defer w.Close()
+
+		Must(w.Write([]byte("data")))
+		buf := make([]byte, 10)
+		n := Must(processors.Reader(ctx, r).Read(buf))
+		Expect(string(buf[:n])).To(Equal("data"))
+		Expect(p.releases.Load()).To(Equal(int32(0)))
+		Expect(p.allocs.Load()).To(Equal(int32(0)))
+	}, SpecTimeout(2*time.Second))
+
+	It("reads without deadline support", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+
+		buf := make([]byte, 10)
+		n := Must(processors.Reader(ctx, bytes.NewBufferString("data")).Read(buf))
+		Expect(string(buf[:n])).To(Equal("data"))
+		Expect(p.releases.Load()).To(Equal(int32(0)))
+
+		r, w := io.Pipe()
+		defer r.Close()
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("more"))
+		}()
+		n = Must(processors.Reader(ctx, r).Read(buf))
+		Expect(string(buf[:n])).To(Equal("more"))
+		Expect(p.releases.Load()).To(Equal(int32(1)))
+		Expect(p.allocs.Load()).To(Equal(int32(1)))
+	}, SpecTimeout(2*time.Second))
+
+	It("keeps deadline of caller", func(sctx SpecContext) {
+		ctx, cancel := context.WithTimeout(processors.WithPool(sctx, p), 100*time.Millisecond)
+		defer cancel()
+		r, w := Must2(os.Pipe())
+		defer r.Close()
+		defer w.Close()
+
+		reader := processors.Reader(ctx, r)
+		MustBeSuccessful(reader.(processors.ReadDeadliner).SetReadDeadline(time.Now().Add(time.Second)))
+		_, err := reader.Read(make([]byte, 10))
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+
+		// the deadline of the caller is restored after the interruption
+		reader = processors.Reader(processors.WithPool(sctx, p), r)
+		start := time.Now()
+		MustBeSuccessful(reader.(processors.ReadDeadliner).SetReadDeadline(start.Add(200 * time.Millisecond)))
+		_, err = reader.Read(make([]byte, 10))
+		Expect(err).To(MatchError(os.ErrDeadlineExceeded))
+		Expect(time.Since(start)).To(BeNumerically(">=", 200*time.Millisecond))
+	}, SpecTimeout(2*time.Second))
+
+	It("is self-terminating only with deadline", func(sctx SpecContext) {
+		rp := &recordingPool{}
+		ctx := processors.WithPool(sctx, rp)
+		r, w := Must2(os.Pipe())
+		defer r.Close()
+		defer w.Close()
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("data"))
+		}()
+		reader := processors.Reader(ctx, r)
+		Must(reader.Read(make([]byte, 10)))
+		Expect(rp.selfTerminating).To(Equal([]bool{false}))
+
+		MustBeSuccessful(reader.(processors.ReadDeadliner).SetReadDeadline(time.Now().Add(time.Second)))
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("data"))
+		}()
+		Must(reader.Read(make([]byte, 10)))
+		Expect(rp.selfTerminating).To(Equal([]bool{false, true}))
+	}, SpecTimeout(2*time.Second))
+
+	It("writes to pipe", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		r, w := Must2(os.Pipe())
+		defer r.Close()
+
+		go func() {
+			processors.Writer(ctx, w).Write([]byte("data"))
+			w.Close()
+		}()
+		Expect(string(Must(io.ReadAll(r)))).To(Equal("data"))
+	}, SpecTimeout(2*time.Second))
+
+	It("interrupts read on cancel", func(sctx SpecContext) {
+		ctx, cancel := context.WithTimeout(processors.WithPool(sctx, p), 100*time.Millisecond)
+		defer cancel()
+		r, w := Must2(os.Pipe())
+		defer r.Close()
+		defer w.Close()
+
+		_, err := processors.Reader(ctx, r).Read(make([]byte, 10))
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(p.releases.Load()).To(Equal(int32(1)))
+		Expect(p.allocs.Load()).To(Equal(int32(1)))
+
+		_, err = processors.Reader(ctx, r).Read(make([]byte, 10))
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(p.releases.Load()).To(Equal(int32(1)))
+
+		// the pipe is still usable
+		Must(w.Write([]byte("data")))
+		buf := make([]byte, 10)
+		n := Must(r.Read(buf))
+		Expect(string(buf[:n])).To(Equal("data"))
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			w.Write([]byte("more"))
+		}()
+		n = Must(processors.Reader(processors.WithPool(sctx, p), r).Read(buf))
+		Expect(string(buf[:n])).To(Equal("more"))
+	}, SpecTimeout(2*time.Second))
+
+	It("handles connections", func(sctx SpecContext) {
+		ctx, cancel := context.WithCancel(processors.WithPool(sctx, p))
+		c1, c2 := net.Pipe()
+		defer c1.Close()
+		defer c2.Close()
+
+		conn := processors.Conn(ctx, c1)
+		go func() {
+			defer GinkgoRecover()
+			buf := make([]byte, 10)
+			n := Must(c2.Read(buf))
+			Must(c2.Write(buf[:n]))
+		}()
+		Must(conn.Write([]byte("ping")))
+		buf := make([]byte, 10)
+		n := Must(conn.Read(buf))
+		Expect(string(buf[:n])).To(Equal("ping"))
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+		_, err := conn.Read(buf)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(p.releases.Load()).To(BeNumerically(">=", 1))
+		Expect(p.allocs.Load()).To(Equal(p.releases.Load()))
+
+		// the connection is still usable with another context
+		conn = processors.Conn(processors.WithPool(sctx, p), c1)
+		go func() {
+			defer GinkgoRecover()
+			Must(c2.Write([]byte("pong")))
+		}()
+		n = Must(conn.Read(buf))
+		Expect(string(buf[:n])).To(Equal("pong"))
+	}, SpecTimeout(2*time.Second))
+})
@@ -14,21 +14,33 @@ type Monitor interface {
 // NewMonitor creates a new Monitor working on
 // a Pool. The pool must be bound to the context.Context.
 func NewMonitor() Monitor {
-	return syncutils.NewMutexMonitor(limithandler{})
+	h := &limithandler{}
+	return bind(h, syncutils.NewMutexMonitor(h))
 }
 
-type limithandler struct{}
+// limithandler releases and allocates pool capacity
+// for blocking operations on the synchronization
+// object obj.
+type limithandler struct {
+	obj any
+}
 
 var _ utils.WaitingHandler = (*limithandler)(nil)
 
-func (l limithandler) Release(ctx context.Context) {
+// bind binds the synchronization object to the handler.
+func bind[T any](h *limithandler, obj T) T {
+	h.obj = obj
+	return obj
+}
+
+func (l *limithandler) Release(ctx context.Context) {
 	p := GetPool(ctx)
 	if p != nil {
-		p.Release(ctx)
+		release(ctx, p, l.obj)
 	}
 }
 
-func (l limithandler) Alloc(ctx context.Context) error {
+func (l *limithandler) Alloc(ctx context.Context) error {
 	p := GetPool(ctx)
 	if p != nil {
 		return p.Alloc(ctx)
@@ -19,5 +19,6 @@ type Mutex interface {
 // NewMutex creates a new Mutex working on
 // a Pool. The pool must be bound to the context.Context.
 func NewMutex() Mutex {
-	return syncutils.NewMutex2(NewMonitor())
+	h := &limithandler{}
+	return bind(h, syncutils.NewMutex2(syncutils.NewMutexMonitor(h)))
 }
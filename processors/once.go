@@ -0,0 +1,128 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/mandelsoft/goutils/optionutils"
+)
+
+// Once executes a function only once. Concurrent callers
+// wait for the first one to finish the execution. While waiting,
+// the pool bound to the context.Context is released.
+// The error of the execution is cached, too, as long
+// as retry on error is not enabled.
+type Once struct {
+	monitor Monitor
+	retry   bool
+	running bool
+	done    bool
+	err     error
+}
+
+// NewOnce creates a new Once.
+func NewOnce() *Once {
+	return &Once{monitor: NewMonitor()}
+}
+
+// SetRetryOnError enables (default) or disables the repeated
+// execution after a failed one. If enabled, the next call
+// (or a waiting one) executes the function again.
+func (o *Once) SetRetryOnError(b ...bool) *Once {
+	o.monitor.Lock()
+	defer o.monitor.Unlock()
+	o.retry = optionutils.BoolOption(b...)
+	return o
+}
+
+// Do executes the function, if it has not yet been
+// (successfully) executed, and returns its error.
+// An error is returned, too, if the context is cancelled
+// while waiting for the execution by another Go routine.
+func (o *Once) Do(ctx context.Context, f func(ctx context.Context) error) error {
+	o.monitor.Lock()
+	for !o.done && o.running {
+		err := o.monitor.Wait(ctx)
+		if err != nil {
+			o.monitor.Unlock()
+			return err
+		}
+	}
+	if o.done {
+		defer o.monitor.Unlock()
+		return o.err
+	}
+	o.running = true
+	o.monitor.Unlock()
+
+	var err error
+	completed := false
+	defer func() {
+		o.monitor.Lock()
+		o.running = false
+		// a panic is no regular execution
+		if completed && (err == nil || !o.retry) {
+			o.done = true
+			o.err = err
+		}
+		o.monitor.SignalAll()
+		o.monitor.Unlock()
+	}()
+	err = f(ctx)
+	completed = true
+	return err
+}
+
+// IsDone returns whether the function has been executed.
+func (o *Once) IsDone() bool {
+	o.monitor.Lock()
+	defer o.monitor.Unlock()
+	return o.done
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Lazy provides a value computed by the first caller.
+// Concurrent callers wait for the computation, releasing
+// the pool bound to the context.Context.
+// The value or error is cached, as long as retry on error is
+// not enabled.
+type Lazy[T any] struct {
+	once  *Once
+	init  func(ctx context.Context) (T, error)
+	value T
+}
+
+// NewLazy creates a new Lazy using the given function
+// to compute the value.
+func NewLazy[T any](init func(ctx context.Context) (T, error)) *Lazy[T] {
+	return &Lazy[T]{once: NewOnce(), init: init}
+}
+
+// SetRetryOnError enables (default) or disables the repeated
+// computation after a failed one.
+func (l *Lazy[T]) SetRetryOnError(b ...bool) *Lazy[T] {
+	l.once.SetRetryOnError(b...)
+	return l
+}
+
+// Get returns the value, computing it on the first call.
+func (l *Lazy[T]) Get(ctx context.Context) (T, error) {
+	var _nil T
+
+	err := l.once.Do(ctx, func(ctx context.Context) error {
+		v, err := l.init(ctx)
+		if err == nil {
+			l.value = v
+		}
+		return err
+	})
+	if err != nil {
+		return _nil, err
+	}
+	return l.value, nil
+}
+
+// IsDone returns whether the value has been computed.
+func (l *Lazy[T]) IsDone() bool {
+	return l.once.IsDone()
+}
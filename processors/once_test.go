@@ -0,0 +1,122 @@
+package processors_test
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+	"github.com/mandelsoft/jobscheduler/syncutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Once Test Environment", func() {
+	var p *countingPool
+
+	BeforeEach(func() {
+		p = &countingPool{}
+	})
+
+	It("executes once", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		once := processors.NewOnce()
+		var count atomic.Int32
+
+		wg := syncutils.NewWaitGroup()
+		wg.Add(3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				defer GinkgoRecover()
+				MustBeSuccessful(once.Do(ctx, func(ctx context.Context) error {
+					time.Sleep(100 * time.Millisecond)
+					count.Add(1)
+					return nil
+				}))
+				wg.Done()
+			}()
+		}
+		MustBeSuccessful(wg.Wait(ctx))
+		Expect(count.Load()).To(Equal(int32(1)))
+		Expect(once.IsDone()).To(BeTrue())
+		Expect(p.releases.Load()).To(Equal(int32(2)))
+		Expect(p.allocs.Load()).To(Equal(int32(2)))
+	}, SpecTimeout(2*time.Second))
+
+	It("caches error", func(ctx SpecContext) {
+		once := processors.NewOnce()
+		var count atomic.Int32
+		f := func(ctx context.Context) error {
+			return fmt.Errorf("failed %d", count.Add(1))
+		}
+		Expect(once.Do(ctx, f)).To(MatchError("failed 1"))
+		Expect(once.Do(ctx, f)).To(MatchError("failed 1"))
+	}, SpecTimeout(2*time.Second))
+
+	It("retries on error", func(ctx SpecContext) {
+		once := processors.NewOnce().SetRetryOnError()
+		var count atomic.Int32
+		f := func(ctx context.Context) error {
+			if n := count.Add(1); n < 3 {
+				return fmt.Errorf("failed %d", n)
+			}
+			return nil
+		}
+		Expect(once.Do(ctx, f)).To(MatchError("failed 1"))
+		Expect(once.IsDone()).To(BeFalse())
+		Expect(once.Do(ctx, f)).To(MatchError("failed 2"))
+		MustBeSuccessful(once.Do(ctx, f))
+		MustBeSuccessful(once.Do(ctx, f))
+		Expect(count.Load()).To(Equal(int32(3)))
+	}, SpecTimeout(2*time.Second))
+
+	It("does not finish on panic", func(ctx SpecContext) {
+		once := processors.NewOnce()
+		Expect(func() {
+			once.Do(ctx, func(ctx context.Context) error { panic("failed") })
+		}).To(PanicWith("failed"))
+		Expect(once.IsDone()).To(BeFalse())
+		MustBeSuccessful(once.Do(ctx, func(ctx context.Context) error { return nil }))
+	}, SpecTimeout(2*time.Second))
+
+	It("cancels waiting", func(sctx SpecContext) {
+		once := processors.NewOnce()
+		go once.Do(sctx, func(ctx context.Context) error {
+			time.Sleep(500 * time.Millisecond)
+			return nil
+		})
+		time.Sleep(50 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(sctx, 100*time.Millisecond)
+		defer cancel()
+		Expect(once.Do(ctx, func(ctx context.Context) error { return nil })).To(MatchError(context.DeadlineExceeded))
+	}, SpecTimeout(2*time.Second))
+})
+
+var _ = Describe("Lazy Test Environment", func() {
+	It("computes value once", func(ctx SpecContext) {
+		var count atomic.Int32
+		lazy := processors.NewLazy(func(ctx context.Context) (string, error) {
+			return fmt.Sprintf("value %d", count.Add(1)), nil
+		})
+		Expect(lazy.IsDone()).To(BeFalse())
+		Expect(lazy.Get(ctx)).To(Equal("value 1"))
+		Expect(lazy.Get(ctx)).To(Equal("value 1"))
+		Expect(lazy.IsDone()).To(BeTrue())
+	}, SpecTimeout(2*time.Second))
+
+	It("retries on error", func(ctx SpecContext) {
+		var count atomic.Int32
+		lazy := processors.NewLazy(func(ctx context.Context) (int, error) {
+			if n := count.Add(1); n < 2 {
+				return 0, fmt.Errorf("failed %d", n)
+			}
+			return 42, nil
+		}).SetRetryOnError()
+		ExpectError(lazy.Get(ctx)).To(MatchError("failed 1"))
+		Expect(lazy.Get(ctx)).To(Equal(42))
+		Expect(lazy.Get(ctx)).To(Equal(42))
+	}, SpecTimeout(2*time.Second))
+})
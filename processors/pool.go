@@ -16,6 +16,34 @@ type Pool interface {
 	Release(ctx context.Context)
 }
 
+// BlockingPool is an optional interface of a Pool, which
+// accepts the synchronization object a Go routine is waiting for
+// when releasing its capacity.
+type BlockingPool interface {
+	Pool
+	ReleaseFor(ctx context.Context, obj any)
+}
+
+// SelfTerminating is an optional interface of an object
+// passed to BlockingPool.ReleaseFor. It marks blocking
+// operations, which end on their own without the help of other
+// Go routines, like a sleep, a timer or an I/O operation
+// with a deadline.
+// A Go routine waiting for such an object is not stuck.
+type SelfTerminating interface {
+	IsSelfTerminating() bool
+}
+
+// release releases the capacity of the given pool and
+// passes the object waited for, if supported by the pool.
+func release(ctx context.Context, p Pool, obj any) {
+	if b, ok := p.(BlockingPool); ok {
+		b.ReleaseFor(ctx, obj)
+	} else {
+		p.Release(ctx)
+	}
+}
+
 var poolAttr = ctxutils.NewAttribute[Pool]()
 
 func GetPool(ctx context.Context) Pool {
@@ -57,6 +57,14 @@ type StateHandler interface {
 	Block(ctx context.Context)
 }
 
+// BlockingStateHandler is an optional interface of a StateHandler,
+// which gets informed about the synchronization object
+// a blocked Go routine is waiting for.
+type BlockingStateHandler interface {
+	StateHandler
+	BlockOn(ctx context.Context, obj any)
+}
+
 type Processors[E any] struct {
 	lock    synclog.Mutex
 	limiter Limiter[E]
@@ -72,6 +80,7 @@ type Processors[E any] struct {
 
 var _ Pool = (*Processors[int])(nil)
 var _ PoolProvider = (*Processors[int])(nil)
+var _ BlockingPool = (*Processors[int])(nil)
 
 func NewProcessors[E any](creator Creator, limiter Limiter[E], n ...int) *Processors[E] {
 	p := &Processors[E]{
@@ -169,7 +178,15 @@ func (p *Processors[E]) Alloc(ctx context.Context) error {
 }
 
 func (p *Processors[E]) Release(ctx context.Context) {
-	p.handler.Block(ctx)
+	p.ReleaseFor(ctx, nil)
+}
+
+func (p *Processors[E]) ReleaseFor(ctx context.Context, obj any) {
+	if h, ok := p.handler.(BlockingStateHandler); ok {
+		h.BlockOn(ctx, obj)
+	} else {
+		p.handler.Block(ctx)
+	}
 	p.New()
 }
 
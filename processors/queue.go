@@ -43,6 +43,9 @@ type Queue[E any, P queue.QueueElement[E]] interface {
 
 	HasDiscarded() bool
 	HasWaiting() bool
+
+	// Len returns the number of queued elements.
+	Len() int
 }
 
 type _queue[E any, P queue.QueueElement[E]] struct {
@@ -77,6 +80,13 @@ func (q *_queue[E, P]) HasWaiting() bool {
 	return q.queue.HasWaiting()
 }
 
+func (q *_queue[E, P]) Len() int {
+	q.queue.Monitor().Lock()
+	defer q.queue.Monitor().Unlock()
+
+	return q.queue.List().Len()
+}
+
 func (q *_queue[E, P]) Add(elem P) {
 	q.queue.Add(elem)
 }
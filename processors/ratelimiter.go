@@ -0,0 +1,110 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket limiting the rate of
+// operations. The bucket is refilled with a fixed rate
+// up to a maximal burst size. Blocking on the limiter
+// releases the pool bound to the context.Context.
+type RateLimiter struct {
+	lock   sync.Mutex
+	rate   float64
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter allowing
+// rate operations per second with a maximal burst size.
+// The bucket is initially full.
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+	if rate <= 0 {
+		panic("rate must be positive")
+	}
+	if burst <= 0 {
+		panic("burst must be positive")
+	}
+	return &RateLimiter{rate: rate, burst: burst, tokens: float64(burst), last: time.Now()}
+}
+
+// refill adds the tokens accumulated since the last call.
+// It must be called under the lock.
+func (l *RateLimiter) refill(now time.Time) {
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > float64(l.burst) {
+		l.tokens = float64(l.burst)
+	}
+	l.last = now
+}
+
+// Allow takes a token, if available, without blocking.
+func (l *RateLimiter) Allow() bool {
+	return l.AllowN(1)
+}
+
+// AllowN takes n tokens, if available, without blocking.
+// A negative number of tokens is never allowed.
+func (l *RateLimiter) AllowN(n int) bool {
+	if n < 0 {
+		return false
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.refill(time.Now())
+	if l.tokens < float64(n) {
+		return false
+	}
+	l.tokens -= float64(n)
+	return true
+}
+
+// Wait takes a token, blocking until it is available
+// or the context is cancelled.
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	return l.WaitN(ctx, 1)
+}
+
+// WaitN takes n tokens, blocking until they are available
+// or the context is cancelled. The tokens are reserved in advance,
+// so waiting Go routines are served in the order of their calls.
+// If the context is cancelled, the reservation is returned.
+func (l *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if n < 0 {
+		return fmt.Errorf("negative number of tokens %d", n)
+	}
+	if n > l.burst {
+		return fmt.Errorf("%d tokens exceed burst size %d", n, l.burst)
+	}
+
+	l.lock.Lock()
+	l.refill(time.Now())
+	l.tokens -= float64(n)
+	missing := -l.tokens
+	l.lock.Unlock()
+
+	if missing <= 0 {
+		return nil
+	}
+	err := Sleep(ctx, time.Duration(missing/l.rate*float64(time.Second)))
+	if err != nil {
+		l.lock.Lock()
+		l.tokens = min(l.tokens+float64(n), float64(l.burst))
+		l.lock.Unlock()
+	}
+	return err
+}
+
+// Tokens returns the actually available number of tokens.
+func (l *RateLimiter) Tokens() float64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.refill(time.Now())
+	return l.tokens
+}
@@ -0,0 +1,20 @@
+package processors
+
+import (
+	"github.com/mandelsoft/jobscheduler/syncutils"
+)
+
+type RWMutex interface {
+	syncutils.RWMutex
+}
+
+// NewRWMutex creates a new RWMutex working on
+// a Pool. The pool must be bound to the context.Context.
+// Readers and writers waiting for the lock release their
+// pool capacity while blocked.
+// The optional policy (default syncutils.READER_PREFERRING)
+// determines the arbitration between readers and writers.
+func NewRWMutex(policy ...syncutils.RWMutexPolicy) RWMutex {
+	h := &limithandler{}
+	return bind(h, syncutils.NewRWMutex2(syncutils.NewMutexMonitor(h), policy...))
+}
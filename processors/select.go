@@ -0,0 +1,281 @@
+package processors
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+// Waiter is a synchronization object, which
+// can be waited for, like a WaitGroup or a CountDownLatch.
+type Waiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TryLocker is an optional interface of a Locker
+// supporting a non-blocking lock attempt.
+type TryLocker interface {
+	TryLock() bool
+}
+
+type caseKind int
+
+const (
+	caseRecv caseKind = iota
+	caseSend
+	caseLock
+	caseWait
+	caseAfter
+)
+
+type selectCase struct {
+	kind   caseKind
+	ch     reflect.Value
+	value  reflect.Value
+	locker Locker
+	waiter Waiter
+	after  time.Duration
+}
+
+// Selected describes the case fired by a Select.
+type Selected struct {
+	// Index is the index of the fired case in the order
+	// the cases have been added.
+	Index int
+	// Value is the received value for a receive case.
+	Value any
+	// Ok is false, if a receive case fired because
+	// of a closed channel.
+	Ok bool
+}
+
+// Select waits for the first of several blocking operations,
+// like channel receives and sends, locker acquisitions,
+// waiters (for example a WaitGroup) and timers.
+// While blocked, the pool capacity bound to the context.Context
+// is released once and allocated again after a case fired.
+//
+// Lockers and waiters are waited for by separate Go routines
+// not using the pool. If another case fires first, a lock
+// acquired in the meantime is released again.
+// This is a known limitation: like syncutils.Select, a blocking
+// Wait still costs one Go routine per locker and waiter case.
+// Only channel and timer cases are waited for without
+// additional Go routines.
+//
+// A Select with an After case ends on its own, therefore
+// it is a SelfTerminating operation and a job waiting for
+// it is not considered deadlocked.
+type Select struct {
+	cases []*selectCase
+}
+
+// NewSelect creates a new empty Select.
+func NewSelect() *Select {
+	return &Select{}
+}
+
+// Receive adds a case receiving from the given channel.
+// The channel must support receive operations.
+func (s *Select) Receive(ch any) *Select {
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.RecvDir == 0 {
+		panic(fmt.Sprintf("%T is no receive channel", ch))
+	}
+	s.cases = append(s.cases, &selectCase{kind: caseRecv, ch: v})
+	return s
+}
+
+// Send adds a case sending a value to the given channel.
+// The channel must support send operations and
+// the value must be assignable to its element type.
+func (s *Select) Send(ch any, value any) *Select {
+	v := reflect.ValueOf(ch)
+	if v.Kind() != reflect.Chan || v.Type().ChanDir()&reflect.SendDir == 0 {
+		panic(fmt.Sprintf("%T is no send channel", ch))
+	}
+	e := reflect.ValueOf(value)
+	if !e.IsValid() {
+		e = reflect.Zero(v.Type().Elem())
+	}
+	if !e.Type().AssignableTo(v.Type().Elem()) {
+		panic(fmt.Sprintf("%T not assignable to element type of %T", value, ch))
+	}
+	s.cases = append(s.cases, &selectCase{kind: caseSend, ch: v, value: e})
+	return s
+}
+
+// Lock adds a case acquiring the given Locker.
+// If this case fires, the lock is held by the caller.
+func (s *Select) Lock(l Locker) *Select {
+	s.cases = append(s.cases, &selectCase{kind: caseLock, locker: l})
+	return s
+}
+
+// WaitFor adds a case waiting for the given Waiter.
+// Because a waiter might be tried with an already cancelled
+// context, it should not have side effects, like a Barrier.
+func (s *Select) WaitFor(w Waiter) *Select {
+	s.cases = append(s.cases, &selectCase{kind: caseWait, waiter: w})
+	return s
+}
+
+// After adds a case firing after the given duration.
+// The timer is started when the select is executed.
+func (s *Select) After(d time.Duration) *Select {
+	s.cases = append(s.cases, &selectCase{kind: caseAfter, after: d})
+	return s
+}
+
+func (s *Select) String() string {
+	return fmt.Sprintf("select on %d cases", len(s.cases))
+}
+
+func (s *Select) IsSelfTerminating() bool {
+	for _, c := range s.cases {
+		if c.kind == caseAfter {
+			return true
+		}
+	}
+	return false
+}
+
+// Wait blocks until the first case fires or the context is
+// cancelled. It reports the fired case.
+func (s *Select) Wait(ctx context.Context) (*Selected, error) {
+	if len(s.cases) == 0 {
+		return nil, errors.New("no select cases")
+	}
+
+	if r := s.try(); r != nil {
+		return r, nil
+	}
+
+	pool := GetPool(ctx)
+	if pool != nil {
+		release(ctx, pool, s)
+	}
+
+	r, err := s.wait(ctx)
+
+	if pool != nil {
+		err2 := pool.Alloc(ctx)
+		if err == nil {
+			err = err2
+		}
+	}
+	return r, err
+}
+
+// try checks all cases without blocking.
+func (s *Select) try() *Selected {
+	var cases []reflect.SelectCase
+	var index []int
+	for i, c := range s.cases {
+		switch c.kind {
+		case caseRecv:
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: c.ch})
+			index = append(index, i)
+		case caseSend:
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectSend, Chan: c.ch, Send: c.value})
+			index = append(index, i)
+		case caseLock:
+			if t, ok := c.locker.(TryLocker); ok && t.TryLock() {
+				return &Selected{Index: i, Ok: true}
+			}
+		case caseWait:
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			if c.waiter.Wait(ctx) == nil {
+				return &Selected{Index: i, Ok: true}
+			}
+		case caseAfter:
+			if c.after <= 0 {
+				return &Selected{Index: i, Ok: true}
+			}
+		}
+	}
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectDefault})
+	chosen, v, ok := reflect.Select(cases)
+	if chosen == len(cases)-1 {
+		return nil
+	}
+	return s.selected(index[chosen], v, ok)
+}
+
+func (s *Select) wait(ctx context.Context) (*Selected, error) {
+	// the Go routines waiting for lockers and waiters must not
+	// use the pool, it is already released by the select.
+	octx := ctx
+	if octx == nil {
+		octx = context.Background()
+	}
+	octx, cancel := context.WithCancel(WithPool(octx, nil))
+	defer cancel()
+
+	cases := make([]reflect.SelectCase, len(s.cases))
+	results := map[int]chan error{}
+	for i, c := range s.cases {
+		switch c.kind {
+		case caseRecv:
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: c.ch}
+		case caseSend:
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectSend, Chan: c.ch, Send: c.value}
+		case caseLock, caseWait:
+			ch := make(chan error, 1)
+			results[i] = ch
+			go func() {
+				if c.kind == caseLock {
+					ch <- c.locker.Lock(octx)
+				} else {
+					ch <- c.waiter.Wait(octx)
+				}
+			}()
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
+		case caseAfter:
+			t := time.NewTimer(c.after)
+			defer t.Stop()
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(t.C)}
+		}
+	}
+	if ctx != nil {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	}
+
+	chosen, v, ok := reflect.Select(cases)
+	cancel()
+
+	// release locks acquired by cases not selected.
+	for i, ch := range results {
+		if i != chosen && s.cases[i].kind == caseLock {
+			go func(l Locker) {
+				if <-ch == nil {
+					l.Unlock()
+				}
+			}(s.cases[i].locker)
+		}
+	}
+
+	if chosen == len(s.cases) {
+		return nil, ctx.Err()
+	}
+	if ch := results[chosen]; ch != nil {
+		if err := v.Interface(); err != nil {
+			return nil, err.(error)
+		}
+		return &Selected{Index: chosen, Ok: true}, nil
+	}
+	return s.selected(chosen, v, ok), nil
+}
+
+func (s *Select) selected(i int, v reflect.Value, ok bool) *Selected {
+	r := &Selected{Index: i, Ok: true}
+	if s.cases[i].kind == caseRecv {
+		r.Value = v.Interface()
+		r.Ok = ok
+	}
+	return r
+}
@@ -0,0 +1,139 @@
+package processors_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+// countingPool counts the Alloc and Release calls.
+type countingPool struct {
+	allocs   atomic.Int32
+	releases atomic.Int32
+}
+
+func (p *countingPool) GetPool() processors.Pool {
+	return p
+}
+
+func (p *countingPool) Alloc(ctx context.Context) error {
+	p.allocs.Add(1)
+	return nil
+}
+
+func (p *countingPool) Release(ctx context.Context) {
+	p.releases.Add(1)
+}
+
+var _ = Describe("Select Test Environment", func() {
+	var p *countingPool
+
+	BeforeEach(func() {
+		p = &countingPool{}
+	})
+
+	It("is self-terminating with timer case", func() {
+		ch := make(chan int)
+		Expect(processors.NewSelect().Receive(ch).IsSelfTerminating()).To(BeFalse())
+		Expect(processors.NewSelect().Receive(ch).After(time.Second).IsSelfTerminating()).To(BeTrue())
+	})
+
+	It("receives without blocking", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		ch := make(chan int, 1)
+		ch <- 5
+
+		r := Must(processors.NewSelect().After(time.Second).Receive(ch).Wait(ctx))
+		Expect(r).To(Equal(&processors.Selected{Index: 1, Value: 5, Ok: true}))
+		Expect(p.releases.Load()).To(Equal(int32(0)))
+	}, SpecTimeout(2*time.Second))
+
+	It("releases pool once", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		ch := make(chan string)
+		send := make(chan int)
+		m := processors.NewMutex()
+		MustBeSuccessful(m.Lock(ctx))
+		wg := processors.NewWaitGroup()
+		wg.Add(1)
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			ch <- "done"
+		}()
+		r := Must(processors.NewSelect().
+			Lock(m).
+			WaitFor(wg).
+			Send(send, 1).
+			Receive(ch).
+			Wait(ctx))
+		Expect(r).To(Equal(&processors.Selected{Index: 3, Value: "done", Ok: true}))
+		Expect(p.releases.Load()).To(Equal(int32(1)))
+		Expect(p.allocs.Load()).To(Equal(int32(1)))
+		m.Unlock()
+		wg.Done()
+	}, SpecTimeout(2*time.Second))
+
+	It("sends", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		ch := make(chan int)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			<-ch
+		}()
+		r := Must(processors.NewSelect().Receive(make(chan int)).Send(ch, 1).Wait(ctx))
+		Expect(r.Index).To(Equal(1))
+	}, SpecTimeout(2*time.Second))
+
+	It("locks", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		m := processors.NewMutex()
+		MustBeSuccessful(m.Lock(ctx))
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			m.Unlock()
+		}()
+		r := Must(processors.NewSelect().Receive(make(chan int)).Lock(m).Wait(ctx))
+		Expect(r.Index).To(Equal(1))
+		Expect(m.(processors.TryLocker).TryLock()).To(BeFalse())
+		m.Unlock()
+	}, SpecTimeout(2*time.Second))
+
+	It("waits for wait group", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		wg := processors.NewWaitGroup()
+		wg.Add(1)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			wg.Done()
+		}()
+		r := Must(processors.NewSelect().After(time.Second).WaitFor(wg).Wait(ctx))
+		Expect(r.Index).To(Equal(1))
+	}, SpecTimeout(2*time.Second))
+
+	It("times out and releases unselected locks", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		m := processors.NewMutex()
+		MustBeSuccessful(m.Lock(ctx))
+
+		r := Must(processors.NewSelect().Lock(m).After(100 * time.Millisecond).Wait(ctx))
+		Expect(r.Index).To(Equal(1))
+		m.Unlock()
+		time.Sleep(100 * time.Millisecond)
+		Expect(m.(processors.TryLocker).TryLock()).To(BeTrue())
+	}, SpecTimeout(2*time.Second))
+
+	It("is cancelled", func(sctx SpecContext) {
+		ctx, cancel := context.WithTimeout(processors.WithPool(sctx, p), 100*time.Millisecond)
+		defer cancel()
+
+		ExpectError(processors.NewSelect().Receive(make(chan int)).Wait(ctx)).To(MatchError(context.DeadlineExceeded))
+		Expect(p.releases.Load()).To(Equal(int32(1)))
+		Expect(p.allocs.Load()).To(Equal(int32(1)))
+	}, SpecTimeout(2*time.Second))
+})
@@ -0,0 +1,18 @@
+package processors
+
+import (
+	"github.com/mandelsoft/jobscheduler/syncutils"
+)
+
+type Semaphore interface {
+	syncutils.Semaphore
+}
+
+// NewSemaphore creates a new weighted Semaphore working on
+// a Pool. The pool must be bound to the context.Context.
+// A Go routine blocked in Acquire releases its pool capacity
+// and allocates it again before continuing.
+func NewSemaphore(n int) Semaphore {
+	h := &limithandler{}
+	return bind(h, syncutils.NewSemaphore(n, h))
+}
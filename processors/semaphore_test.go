@@ -0,0 +1,38 @@
+package processors_test
+
+import (
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+	"github.com/mandelsoft/jobscheduler/syncutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Semaphore Test Environment", func() {
+	var p processors.Pool
+	var s processors.Semaphore
+
+	BeforeEach(func() {
+		p = processors.NewDefaultPool()
+		s = processors.NewSemaphore(2)
+	})
+
+	It("waits for weight", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		MustBeSuccessful(s.Acquire(ctx, 2))
+		wg := syncutils.NewWaitGroup()
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			MustBeSuccessful(s.Acquire(ctx, 1))
+			s.Release(1)
+			wg.Done()
+		}()
+		time.Sleep(100 * time.Millisecond)
+		s.Release(2)
+		MustBeSuccessful(wg.Wait(ctx))
+		Expect(s.Available()).To(Equal(2))
+	}, SpecTimeout(2*time.Second))
+})
@@ -25,7 +25,7 @@ func Sleep(ctx context.Context, d time.Duration) error {
 	default:
 	}
 
-	pool.Release(ctx)
+	release(ctx, pool, sleeping(d))
 	select {
 	case <-ctx.Done():
 		pool.Alloc(ctx)
@@ -34,3 +34,14 @@ func Sleep(ctx context.Context, d time.Duration) error {
 		return pool.Alloc(ctx)
 	}
 }
+
+// sleeping describes a blocking Sleep operation.
+type sleeping time.Duration
+
+func (s sleeping) String() string {
+	return "sleep " + time.Duration(s).String()
+}
+
+func (s sleeping) IsSelfTerminating() bool {
+	return true
+}
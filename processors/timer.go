@@ -0,0 +1,100 @@
+package processors
+
+import (
+	"context"
+	"time"
+)
+
+// After waits for the given duration and returns the actual time.
+// While waiting, the pool bound to the context.Context is released.
+func After(ctx context.Context, d time.Duration) (time.Time, error) {
+	t := NewTimer(d)
+	defer t.Stop()
+	return t.Wait(ctx)
+}
+
+// Timer is a time.Timer, whose Wait operation
+// releases the pool bound to the context.Context
+// while waiting.
+type Timer struct {
+	timer *time.Timer
+}
+
+// NewTimer creates a new Timer firing after the given duration.
+func NewTimer(d time.Duration) *Timer {
+	return &Timer{timer: time.NewTimer(d)}
+}
+
+// C provides the channel the time is delivered on.
+func (t *Timer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Wait waits until the timer fires and returns the fire time.
+func (t *Timer) Wait(ctx context.Context) (time.Time, error) {
+	v, _, err := ReceiveFromChannelFor(ctx, t.timer.C, t)
+	return v, err
+}
+
+func (t *Timer) String() string {
+	return "timer"
+}
+
+func (t *Timer) IsSelfTerminating() bool {
+	return true
+}
+
+// Stop prevents the timer from firing.
+// It returns false, if the timer has already expired or been stopped.
+func (t *Timer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// Reset changes the timer to expire after the given duration.
+// It returns true, if the timer had been active.
+func (t *Timer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Ticker is a time.Ticker, whose Wait operation
+// releases the pool bound to the context.Context
+// while waiting.
+type Ticker struct {
+	ticker *time.Ticker
+}
+
+// NewTicker creates a new Ticker delivering ticks with the given period.
+func NewTicker(d time.Duration) *Ticker {
+	return &Ticker{ticker: time.NewTicker(d)}
+}
+
+// C provides the channel the ticks are delivered on.
+func (t *Ticker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+// Wait waits for the next tick and returns its time.
+func (t *Ticker) Wait(ctx context.Context) (time.Time, error) {
+	v, _, err := ReceiveFromChannelFor(ctx, t.ticker.C, t)
+	return v, err
+}
+
+func (t *Ticker) String() string {
+	return "ticker"
+}
+
+func (t *Ticker) IsSelfTerminating() bool {
+	return true
+}
+
+// Stop turns off the ticker.
+func (t *Ticker) Stop() {
+	t.ticker.Stop()
+}
+
+// Reset stops the ticker and resets its period.
+func (t *Ticker) Reset(d time.Duration) {
+	t.ticker.Reset(d)
+}
@@ -0,0 +1,110 @@
+package processors_test
+
+import (
+	"context"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Timer Test Environment", func() {
+	var p *countingPool
+
+	BeforeEach(func() {
+		p = &countingPool{}
+	})
+
+	It("waits for timer", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		start := time.Now()
+		t := Must(processors.After(ctx, 100*time.Millisecond))
+		Expect(t.Sub(start)).To(BeNumerically(">=", 100*time.Millisecond))
+		Expect(p.releases.Load()).To(Equal(int32(1)))
+		Expect(p.allocs.Load()).To(Equal(int32(1)))
+	}, SpecTimeout(time.Second))
+
+	It("resets timer", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		start := time.Now()
+		t := processors.NewTimer(time.Hour)
+		Expect(t.Reset(100 * time.Millisecond)).To(BeTrue())
+		Expect(Must(t.Wait(ctx)).Sub(start)).To(BeNumerically(">=", 100*time.Millisecond))
+		Expect(t.Stop()).To(BeFalse())
+	}, SpecTimeout(time.Second))
+
+	It("cancels timer", func(sctx SpecContext) {
+		ctx, cancel := context.WithTimeout(processors.WithPool(sctx, p), 100*time.Millisecond)
+		defer cancel()
+		ExpectError(processors.After(ctx, time.Hour)).To(MatchError(context.DeadlineExceeded))
+		Expect(p.releases.Load()).To(Equal(int32(1)))
+		Expect(p.allocs.Load()).To(Equal(int32(1)))
+	}, SpecTimeout(time.Second))
+
+	It("ticks", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		start := time.Now()
+		t := processors.NewTicker(50 * time.Millisecond)
+		defer t.Stop()
+		for i := 0; i < 3; i++ {
+			Must(t.Wait(ctx))
+		}
+		Expect(time.Now().Sub(start)).To(BeNumerically(">=", 150*time.Millisecond))
+		Expect(p.releases.Load()).To(BeNumerically(">=", 1))
+	}, SpecTimeout(time.Second))
+})
+
+var _ = Describe("RateLimiter Test Environment", func() {
+	var p *countingPool
+
+	BeforeEach(func() {
+		p = &countingPool{}
+	})
+
+	It("allows burst", func() {
+		l := processors.NewRateLimiter(10, 3)
+		Expect(l.Allow()).To(BeTrue())
+		Expect(l.AllowN(2)).To(BeTrue())
+		Expect(l.Allow()).To(BeFalse())
+		time.Sleep(110 * time.Millisecond)
+		Expect(l.Allow()).To(BeTrue())
+	})
+
+	It("limits rate", func(sctx SpecContext) {
+		ctx := processors.WithPool(sctx, p)
+		l := processors.NewRateLimiter(20, 1)
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			MustBeSuccessful(l.Wait(ctx))
+		}
+		Expect(time.Now().Sub(start)).To(BeNumerically(">=", 190*time.Millisecond))
+		Expect(p.releases.Load()).To(Equal(int32(4)))
+		Expect(p.allocs.Load()).To(Equal(int32(4)))
+	}, SpecTimeout(time.Second))
+
+	It("returns reservation on cancel", func(sctx SpecContext) {
+		l := processors.NewRateLimiter(1, 2)
+		Expect(l.AllowN(2)).To(BeTrue())
+
+		ctx, cancel := context.WithTimeout(processors.WithPool(sctx, p), 100*time.Millisecond)
+		defer cancel()
+		ExpectError(l.WaitN(ctx, 2)).To(MatchError(context.DeadlineExceeded))
+		Expect(l.Tokens()).To(BeNumerically("~", 0.1, 0.05))
+	}, SpecTimeout(time.Second))
+
+	It("rejects exceeding burst", func(ctx SpecContext) {
+		l := processors.NewRateLimiter(1, 2)
+		Expect(l.WaitN(ctx, 3)).To(MatchError("3 tokens exceed burst size 2"))
+	})
+
+	It("rejects negative tokens", func(ctx SpecContext) {
+		l := processors.NewRateLimiter(1, 2)
+		Expect(l.WaitN(ctx, -1)).To(MatchError("negative number of tokens -1"))
+		Expect(l.AllowN(-1)).To(BeFalse())
+		Expect(l.Tokens()).To(BeNumerically("<=", 2))
+		Expect(l.AllowN(2)).To(BeTrue())
+		Expect(l.AllowN(1)).To(BeFalse())
+	})
+})
@@ -16,7 +16,8 @@ type WaitGroup struct {
 // NewWaitGroup creates a new WaitGroup working on
 // a Pool. The pool must be bound to the context.Context.
 func NewWaitGroup() *WaitGroup {
-	return &WaitGroup{waiting: utils.NewWaiting(&limithandler{})}
+	h := &limithandler{}
+	return bind(h, &WaitGroup{waiting: utils.NewWaiting(h)})
 }
 
 func (wg *WaitGroup) Add(delta int) {
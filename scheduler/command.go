@@ -0,0 +1,164 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mandelsoft/goutils/optionutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+)
+
+// DEFAULT_GRACE_PERIOD is the default time a cancelled
+// command gets to terminate after SIGTERM before it is killed.
+const DEFAULT_GRACE_PERIOD = 10 * time.Second
+
+// ExitError is reported as job error if a command
+// executed by a CommandRunner exits with a non-zero exit code.
+type ExitError struct {
+	Command  string
+	ExitCode int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("command %q exited with code %d", e.Command, e.ExitCode)
+}
+
+// CommandRunner is a Runner executing an external command.
+// The output of the command (stdout and stderr) is written to the job
+// output. The exit code is provided as job result. A non-zero exit
+// code is reported as *ExitError.
+// If the job is cancelled, the command gets a SIGTERM and is killed
+// after a grace period, or immediately, if there is no grace period.
+type CommandRunner struct {
+	path    string
+	args    []string
+	env     []string
+	dir     string
+	release bool
+	grace   time.Duration
+}
+
+var _ Runner = CommandRunner{}
+
+// Command creates a new CommandRunner for the given command and arguments.
+func Command(path string, args ...string) CommandRunner {
+	return CommandRunner{path: path, args: slices.Clone(args), grace: DEFAULT_GRACE_PERIOD}
+}
+
+func (c CommandRunner) GetPath() string {
+	return c.path
+}
+
+func (c CommandRunner) GetArgs() []string {
+	return slices.Clone(c.args)
+}
+
+// SetArgs sets the arguments of the command.
+func (c CommandRunner) SetArgs(args ...string) CommandRunner {
+	c.args = slices.Clone(args)
+	return c
+}
+
+// AddEnv adds environment variables in the form key=value
+// to the environment inherited from the actual process.
+func (c CommandRunner) AddEnv(env ...string) CommandRunner {
+	c.env = append(slices.Clone(c.env), env...)
+	return c
+}
+
+// SetDir sets the working directory of the command.
+// By default, the working directory of the actual process is used.
+func (c CommandRunner) SetDir(dir string) CommandRunner {
+	c.dir = dir
+	return c
+}
+
+// SetReleaseProcessor enables or disables releasing the processor
+// of the job while waiting for the command to exit. Called without
+// argument it enables the release; it is disabled by default.
+func (c CommandRunner) SetReleaseProcessor(b ...bool) CommandRunner {
+	c.release = optionutils.BoolOption(b...)
+	return c
+}
+
+// SetGracePeriod sets the time a cancelled command gets to terminate
+// after SIGTERM before it is killed (default DEFAULT_GRACE_PERIOD).
+// A period of 0 kills a cancelled command immediately.
+func (c CommandRunner) SetGracePeriod(d time.Duration) CommandRunner {
+	c.grace = d
+	return c
+}
+
+func (c CommandRunner) String() string {
+	return strings.Join(append([]string{c.path}, c.args...), " ")
+}
+
+func (c CommandRunner) Run(ctx SchedulingContext) (Result, error) {
+	cmd := exec.CommandContext(ctx, c.path, c.args...)
+	cmd.Dir = c.dir
+	if len(c.env) > 0 {
+		cmd.Env = append(os.Environ(), c.env...)
+	}
+	cmd.Stdout = ctx
+	cmd.Stderr = ctx
+	if c.grace > 0 {
+		cmd.Cancel = func() error {
+			return cmd.Process.Signal(syscall.SIGTERM)
+		}
+		cmd.WaitDelay = c.grace
+	} else {
+		// the command is killed by default. A WaitDelay of 0
+		// would wait forever for output pipes kept open by
+		// sub processes.
+		cmd.WaitDelay = time.Nanosecond
+	}
+
+	err := cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.release {
+		done := make(chan error, 1)
+		go func() {
+			done <- cmd.Wait()
+		}()
+		// the command must be waited for, even if the job is cancelled.
+		var perr error
+		err, _, perr = processors.ReceiveFromChannelFor(context.WithoutCancel(ctx), done, commandWaiting(c.String()))
+		if perr != nil {
+			return nil, perr
+		}
+	} else {
+		err = cmd.Wait()
+	}
+
+	code := cmd.ProcessState.ExitCode()
+	if ctx.Err() != nil {
+		return code, ctx.Err()
+	}
+	if err != nil {
+		if _, ok := err.(*exec.ExitError); ok {
+			return code, &ExitError{Command: c.String(), ExitCode: code}
+		}
+		return code, err
+	}
+	return code, nil
+}
+
+// commandWaiting describes a job waiting for an external command.
+type commandWaiting string
+
+func (c commandWaiting) String() string {
+	return "command " + string(c)
+}
+
+func (c commandWaiting) IsSelfTerminating() bool {
+	return true
+}
@@ -0,0 +1,110 @@
+package scheduler_test
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/buffered"
+)
+
+var _ = Describe("Command Test Environment", func() {
+	var sched scheduler.Scheduler
+	var buf *bytes.Buffer
+
+	BeforeEach(func() {
+		// the buffered extension provides the job output
+		// written to the job writer, when the job is finished.
+		buf = &bytes.Buffer{}
+		sched = scheduler.New()
+		sched.SetExtension(buffered.New(buf))
+		sched.AddProcessor()
+		sched.Run(nil)
+	})
+
+	AfterEach(func() {
+		sched.Cancel()
+		sched.Wait()
+	})
+
+	apply := func(cmd scheduler.CommandRunner) scheduler.Job {
+		job := Must(sched.Apply(scheduler.DefineJob("cmd", cmd)))
+		MustBeSuccessful(job.Schedule())
+		return job
+	}
+
+	run := func(cmd scheduler.CommandRunner) (scheduler.Result, error, string) {
+		job := apply(cmd)
+		job.Wait()
+		r, err := job.GetResult()
+		return r, err, buf.String()
+	}
+
+	It("streams output", func() {
+		r, err, out := run(scheduler.Command("sh", "-c", "echo out; echo err >&2"))
+		MustBeSuccessful(err)
+		Expect(r).To(Equal(0))
+		Expect(out).To(Equal("- JOB cmd[1] done\n  out\n  err\n"))
+	})
+
+	It("maps exit code", func() {
+		r, err, _ := run(scheduler.Command("sh", "-c", "exit 3"))
+		Expect(r).To(Equal(3))
+		Expect(err).To(Equal(&scheduler.ExitError{Command: "sh -c exit 3", ExitCode: 3}))
+	})
+
+	It("uses environment and directory", func() {
+		dir := GinkgoT().TempDir()
+		r, err, out := run(scheduler.Command("sh", "-c", "echo $TESTVAR; pwd").AddEnv("TESTVAR=value").SetDir(dir))
+		MustBeSuccessful(err)
+		Expect(r).To(Equal(0))
+		Expect(out).To(Equal("- JOB cmd[1] done\n  value\n  " + dir + "\n"))
+	})
+
+	It("releases processor", func() {
+		cmd := scheduler.Command("sleep", "0.3").SetReleaseProcessor()
+		start := time.Now()
+		job1 := apply(cmd)
+		job2 := apply(cmd)
+		job1.Wait()
+		job2.Wait()
+		Expect(time.Now().Sub(start)).To(BeNumerically("<", 550*time.Millisecond))
+	})
+
+	It("terminates cancelled command", func() {
+		job := apply(scheduler.Command("sh", "-c", `trap "echo terminated; exit 5" TERM; while true; do sleep 0.1; done`).SetReleaseProcessor())
+		time.Sleep(200 * time.Millisecond)
+		job.Cancel()
+		job.Wait()
+		_, err := job.GetResult()
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(buf.String()).To(Equal("- JOB cmd[1] failed\n  terminated\n"))
+	})
+
+	It("kills command after grace period", func() {
+		job := apply(scheduler.Command("sh", "-c", `trap "" TERM; while true; do sleep 0.1; done`).SetGracePeriod(200 * time.Millisecond))
+		time.Sleep(200 * time.Millisecond)
+		start := time.Now()
+		job.Cancel()
+		job.Wait()
+		Expect(time.Now().Sub(start)).To(BeNumerically("<", time.Second))
+		_, err := job.GetResult()
+		Expect(err).To(MatchError(context.Canceled))
+	})
+
+	It("kills command immediately without grace period", func() {
+		job := apply(scheduler.Command("sh", "-c", `trap "" TERM; while true; do sleep 0.1; done`).SetGracePeriod(0))
+		time.Sleep(200 * time.Millisecond)
+		start := time.Now()
+		job.Cancel()
+		job.Wait()
+		Expect(time.Now().Sub(start)).To(BeNumerically("<", 500*time.Millisecond))
+		_, err := job.GetResult()
+		Expect(err).To(MatchError(context.Canceled))
+	})
+})
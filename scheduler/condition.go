@@ -3,13 +3,16 @@ package scheduler
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/mandelsoft/jobscheduler/scheduler/condition"
 )
 
 type JobEvent struct {
-	job   Job
-	state State
+	job      Job
+	state    State
+	time     time.Time
+	timeline Timeline
 }
 
 func (e JobEvent) String() string {
@@ -20,6 +23,17 @@ func (e JobEvent) GetState() State {
 	return e.state
 }
 
+// GetTime returns the time the state transition happened.
+func (e JobEvent) GetTime() time.Time {
+	return e.time
+}
+
+// GetTimeline returns the state transitions of the job up to
+// this event.
+func (e JobEvent) GetTimeline() Timeline {
+	return e.timeline
+}
+
 func (e JobEvent) GetJob() Job {
 	return e.job
 }
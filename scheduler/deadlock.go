@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/mandelsoft/jobscheduler/processors"
+)
+
+// BlockedJob describes a job participating in a deadlock.
+type BlockedJob struct {
+	Job   Job
+	State State
+	// WaitingFor is the synchronization object a BLOCKED job
+	// is waiting for, or the start condition of a WAITING job.
+	WaitingFor any
+}
+
+func (b BlockedJob) String() string {
+	if b.WaitingFor == nil {
+		return fmt.Sprintf("%s[%s]", b.Job.GetId(), b.State)
+	}
+	return fmt.Sprintf("%s[%s] waiting for %s", b.Job.GetId(), b.State, describe(b.WaitingFor))
+}
+
+// DeadlockEvent is raised if the scheduler detects
+// a state where no job can progress anymore.
+// There are no running, ready or pending jobs,
+// but blocked jobs, for the configured detection period.
+// Waiting jobs are reported together with the blocked jobs,
+// but do not cause a deadlock on their own, because their
+// conditions may be triggered from outside the scheduler.
+// If a job is blocked in a processors.SelfTerminating operation,
+// the scheduler is not considered stuck.
+type DeadlockEvent struct {
+	Since time.Time
+	Jobs  []BlockedJob
+}
+
+var _ SchedulerEvent = DeadlockEvent{}
+
+func (e DeadlockEvent) String() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "deadlock detected since %s (%d jobs)", e.Since.Format(time.RFC3339), len(e.Jobs))
+	for _, j := range e.Jobs {
+		fmt.Fprintf(&b, "\n  - %s", j)
+	}
+	return b.String()
+}
+
+type DeadlockHandler interface {
+	HandleDeadlock(event DeadlockEvent)
+}
+
+type DeadlockHandlerFunc func(event DeadlockEvent)
+
+func (f DeadlockHandlerFunc) HandleDeadlock(event DeadlockEvent) {
+	f(event)
+}
+
+func describe(o any) string {
+	if s, ok := o.(fmt.Stringer); ok {
+		return s.String()
+	}
+	switch reflect.ValueOf(o).Kind() {
+	case reflect.Pointer, reflect.Chan, reflect.Map, reflect.Func:
+		return fmt.Sprintf("%T(%p)", o, o)
+	default:
+		return fmt.Sprintf("%T", o)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type deadlockDetector struct {
+	period   time.Duration
+	handler  DeadlockHandler
+	since    time.Time
+	reported bool
+}
+
+func (s *scheduler) SetDeadlockDetection(period time.Duration, handler ...DeadlockHandler) {
+	s.lock.Lock()
+	s.deadlock.period = period
+	s.deadlock.handler = nil
+	if len(handler) > 0 {
+		s.deadlock.handler = handler[0]
+	}
+	s.deadlock.since = time.Time{}
+	s.deadlock.reported = false
+	s.lock.Unlock()
+
+	s.watch()
+}
+
+func (s *scheduler) isStuck() bool {
+	if s.running.Len() > 0 || s.ready.Len() > 0 || s.pending.Len() > 0 {
+		return false
+	}
+	// jobs blocked in self-terminating operations, like a sleep,
+	// will progress without the help of other jobs.
+	for j := range s.blocked.Elements() {
+		j.lock.Lock()
+		o, ok := j.blockedOn.(processors.SelfTerminating)
+		j.lock.Unlock()
+		if ok && o.IsSelfTerminating() {
+			return false
+		}
+	}
+	// waiting jobs alone are not stuck, their conditions
+	// may be triggered from outside, for example by a timer.
+	return s.blocked.Len() > 0
+}
+
+func (s *scheduler) blockedJobs() []BlockedJob {
+	var list []BlockedJob
+
+	for j := range s.blocked.Elements() {
+		j.lock.Lock()
+		list = append(list, BlockedJob{Job: j, State: BLOCKED, WaitingFor: j.blockedOn})
+		j.lock.Unlock()
+	}
+	for j := range s.waiting.Elements() {
+		var c any
+		if j.definition.trigger != nil {
+			c = j.definition.trigger
+		}
+		list = append(list, BlockedJob{Job: j, State: WAITING, WaitingFor: c})
+	}
+	return list
+}
+
+// checkDeadlock is called periodically by the watchdog.
+// It reports a deadlock once, if the stuck state is
+// observed for the configured period.
+func (s *scheduler) checkDeadlock(now time.Time) {
+	stuck := s.isStuck()
+
+	s.lock.Lock()
+	d := &s.deadlock
+	if d.period <= 0 {
+		s.lock.Unlock()
+		return
+	}
+	if !stuck {
+		d.since = time.Time{}
+		d.reported = false
+		s.lock.Unlock()
+		return
+	}
+	if d.since.IsZero() {
+		d.since = now
+	}
+	if d.reported || now.Sub(d.since) < d.period {
+		s.lock.Unlock()
+		return
+	}
+	d.reported = true
+	handler := d.handler
+	since := d.since
+	s.lock.Unlock()
+
+	evt := DeadlockEvent{Since: since, Jobs: s.blockedJobs()}
+	log.Warn("{{event}}", "event", evt.String(), "scheduler", s.name)
+	s.raise(evt)
+	if handler != nil {
+		handler.HandleDeadlock(evt)
+	}
+}
@@ -66,3 +66,12 @@ func (j *_JobExtension) Close() error {
 
 func (j *_JobExtension) Start() {
 }
+
+func (j *_JobExtension) HandleJobEvent(e JobEvent) {
+}
+
+func (j *_JobExtension) Heartbeat(info any) {
+}
+
+func (j *_JobExtension) Progress(info ProgressInfo) {
+}
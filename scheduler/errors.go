@@ -0,0 +1,24 @@
+package scheduler
+
+import (
+	"fmt"
+)
+
+// PanicError is reported as job error if the runner of a job panics.
+// It carries the panic value and the stack trace of the panicking
+// Go routine.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("job runner panicked: %v", e.Value)
+}
+
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package scheduler
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SchedulerEvent is an event concerning the scheduler
+// as a whole, like a detected deadlock.
+type SchedulerEvent interface {
+	String() string
+}
+
+type SchedulerEventHandler interface {
+	HandleSchedulerEvent(event SchedulerEvent)
+}
+
+type SchedulerEventHandlerFunc func(event SchedulerEvent)
+
+func (f SchedulerEventHandlerFunc) HandleSchedulerEvent(event SchedulerEvent) {
+	f(event)
+}
+
+// ProcessorEvent is raised whenever processors are
+// added to or removed from the scheduler.
+type ProcessorEvent struct {
+	// Count is the new number of processors.
+	Count int
+}
+
+func (e *ProcessorEvent) String() string {
+	return fmt.Sprintf("processors: %d", e.Count)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (s *scheduler) RegisterHandler(handler SchedulerEventHandler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.handlers = append(s.handlers, handler)
+}
+
+func (s *scheduler) UnregisterHandler(handler SchedulerEventHandler) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for i, h := range s.handlers {
+		if h == handler {
+			s.handlers = append(s.handlers[:i], s.handlers[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *scheduler) raise(e SchedulerEvent) {
+	s.lock.Lock()
+	handlers := slices.Clone(s.handlers)
+	s.lock.Unlock()
+
+	for _, h := range handlers {
+		h.HandleSchedulerEvent(e)
+	}
+}
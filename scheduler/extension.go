@@ -19,9 +19,14 @@ type ExtensionDefinition interface {
 
 type JobExtension interface {
 	GetExtension(typ string) JobExtension
+	// EventHandler is called for all events of the job
+	// before the handlers registered at the job.
+	EventHandler
 
 	Writer() io.Writer
 	Start()
 	SetState(state State)
+	Heartbeat(info any)
+	Progress(info ProgressInfo)
 	Close() error
 }
@@ -4,35 +4,114 @@ import (
 	"bytes"
 	"fmt"
 	"io"
-	"strings"
+	"slices"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/goutils/optionutils"
 	"github.com/mandelsoft/jobscheduler/scheduler"
 	"github.com/mandelsoft/jobscheduler/scheduler/extensions"
 )
 
-const TYPE = "writer"
+const TYPE = "buffered"
+
+// Order describes the order the output blocks of the jobs are emitted.
+type Order int
+
+const (
+	// CREATION_ORDER emits the blocks in the order the jobs have been created.
+	// A slow job delays the output of all jobs created later.
+	CREATION_ORDER Order = iota
+	// COMPLETION_ORDER emits the block of a job as soon as it is finished.
+	COMPLETION_ORDER
+)
+
+// DEFAULT_TIMESTAMP is the default layout used for timestamp prefixes.
+const DEFAULT_TIMESTAMP = "15:04:05.000"
 
 ////////////////////////////////////////////////////////////////////////////////
 
 type Extension struct {
 	extensions.Extension
-	lock      sync.Mutex
-	scheduler scheduler.Scheduler
-	writer    io.Writer
-	blocks    []*JobExtension
+	lock       sync.Mutex
+	scheduler  scheduler.Scheduler
+	writer     io.Writer
+	blocks     []*JobExtension
+	order      Order
+	failedOnly bool
+	head       int
+	tail       int
+	idPrefix   bool
+	timestamps string
 }
 
 var _ scheduler.Extension = (*Extension)(nil)
 
-func New(writer io.Writer, nested ...scheduler.Extension) scheduler.Extension {
+func New(writer io.Writer, nested ...scheduler.Extension) *Extension {
 	e := &Extension{writer: writer}
 	e.Extension = extensions.NewExtension(e, TYPE, nested...)
 	return e
 }
 
+// SetOrder sets the order the job output is emitted.
+// Default is CREATION_ORDER.
+func (e *Extension) SetOrder(o Order) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.order = o
+	return e
+}
+
+// SetFailedOnly enables (default) or disables the restriction
+// of the output to failed jobs. The output of all other jobs
+// is discarded.
+func (e *Extension) SetFailedOnly(b ...bool) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.failedOnly = optionutils.BoolOption(b...)
+	return e
+}
+
+// SetMaxLines limits the output of a job to the first head and
+// the last tail lines. Skipped lines are indicated by a marker line.
+// If both are 0 (default), the complete output is emitted.
+// Negative values are not allowed.
+func (e *Extension) SetMaxLines(head, tail int) *Extension {
+	if head < 0 || tail < 0 {
+		panic("negative line limit")
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.head = head
+	e.tail = tail
+	return e
+}
+
+// SetJobIdPrefix enables (default) or disables prefixing every
+// output line with the job id.
+func (e *Extension) SetJobIdPrefix(b ...bool) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.idPrefix = optionutils.BoolOption(b...)
+	return e
+}
+
+// SetTimestamps enables prefixing every output line with the time
+// it has been written using the given layout (default DEFAULT_TIMESTAMP).
+// An empty layout disables the timestamps.
+func (e *Extension) SetTimestamps(layout ...string) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if len(layout) == 0 {
+		e.timestamps = DEFAULT_TIMESTAMP
+	} else {
+		e.timestamps = layout[0]
+	}
+	return e
+}
+
 func (e *Extension) Setup(s scheduler.Scheduler) error {
 	e.scheduler = s
 	return e.Extension.Setup(s)
@@ -56,7 +135,7 @@ func (e *Extension) JobExtension(id string, jd scheduler.JobDefinition, parent s
 		ext:    e,
 		id:     id,
 		gap:    gap,
-		writer: bytes.NewBuffer(nil),
+		writer: &lineBuffer{},
 	}
 	j.JobExtension, err = extensions.NewJobExtension(j, TYPE, id, jd, e.Extension)
 	if err != nil {
@@ -70,12 +149,52 @@ func (e *Extension) discard() {
 	e.lock.Lock()
 	defer e.lock.Unlock()
 
+	if e.order == COMPLETION_ORDER {
+		e.blocks = slices.DeleteFunc(e.blocks, func(j *JobExtension) bool {
+			if j.done.Load() {
+				e.emit(j)
+				return true
+			}
+			return false
+		})
+		return
+	}
 	for len(e.blocks) > 0 && e.blocks[0].done.Load() {
-		e.blocks[0].emit()
+		e.emit(e.blocks[0])
 		e.blocks = e.blocks[1:]
 	}
 }
 
+func (e *Extension) emit(j *JobExtension) {
+	if e.failedOnly && !scheduler.IsFailed(j.state) {
+		return
+	}
+	fmt.Fprintf(e.writer, "%s- JOB %s %s\n", j.gap, j.id, j.state)
+
+	lines := j.writer.Lines()
+	skipped := 0
+	if (e.head > 0 || e.tail > 0) && len(lines) > e.head+e.tail {
+		skipped = len(lines) - e.head - e.tail
+		lines = append(lines[:e.head:e.head], lines[len(lines)-e.tail:]...)
+	}
+	for i, l := range lines {
+		if skipped > 0 && i == e.head {
+			fmt.Fprintf(e.writer, "%s  ... %d lines skipped ...\n", j.gap, skipped)
+		}
+		prefix := ""
+		if e.timestamps != "" {
+			prefix += l.time.Format(e.timestamps) + " "
+		}
+		if e.idPrefix {
+			prefix += j.id + ": "
+		}
+		fmt.Fprintf(e.writer, "%s  %s%s\n", j.gap, prefix, l.text)
+	}
+	if skipped > 0 && e.tail == 0 {
+		fmt.Fprintf(e.writer, "%s  ... %d lines skipped ...\n", j.gap, skipped)
+	}
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 
 func GetExtension(job scheduler.Job) *JobExtension {
@@ -87,7 +206,7 @@ type JobExtension struct {
 	ext    *Extension
 	gap    string
 	id     string
-	writer *bytes.Buffer
+	writer *lineBuffer
 	state  scheduler.State
 	done   atomic.Bool
 }
@@ -104,13 +223,57 @@ func (j *JobExtension) SetState(state scheduler.State) {
 		j.done.Store(true)
 		j.ext.discard()
 	}
+	j.JobExtension.SetState(state)
 }
 
-func (j *JobExtension) emit() {
-	fmt.Fprintf(j.ext.writer, "%s- JOB %s %s\n", j.gap, j.id, j.state)
-	s := j.writer.String()
-	if strings.HasSuffix(s, "\n") {
-		s = s[:len(s)-1]
+////////////////////////////////////////////////////////////////////////////////
+
+type line struct {
+	time time.Time
+	text string
+}
+
+// lineBuffer keeps the output of a job as lines
+// together with the time they have been started.
+type lineBuffer struct {
+	lock    sync.Mutex
+	lines   []line
+	partial bytes.Buffer
+	started time.Time
+}
+
+func (b *lineBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	n := len(p)
+	now := time.Now()
+	for len(p) > 0 {
+		if b.partial.Len() == 0 {
+			b.started = now
+		}
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			b.partial.Write(p)
+			break
+		}
+		b.partial.Write(p[:i])
+		b.lines = append(b.lines, line{b.started, b.partial.String()})
+		b.partial.Reset()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// Lines returns the complete output including
+// an unterminated last line.
+func (b *lineBuffer) Lines() []line {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	lines := slices.Clone(b.lines)
+	if b.partial.Len() > 0 {
+		lines = append(lines, line{b.started, b.partial.String()})
 	}
-	fmt.Fprint(j.ext.writer, j.gap+"  "+strings.ReplaceAll(s, "\n", "\n"+j.gap+"  ")+"\n")
+	return lines
 }
@@ -0,0 +1,170 @@
+package buffered_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/buffered"
+)
+
+func output(lines int, delay time.Duration, err error) scheduler.Runner {
+	return scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+		time.Sleep(delay)
+		for i := 1; i <= lines; i++ {
+			fmt.Fprintf(ctx, "line %d\n", i)
+		}
+		return nil, err
+	})
+}
+
+var _ = Describe("Buffered Test Environment", func() {
+	var sched scheduler.Scheduler
+	var buf *bytes.Buffer
+	var ext *buffered.Extension
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		ext = buffered.New(buf)
+		sched = scheduler.New()
+		sched.SetExtension(ext)
+		sched.AddProcessor(2)
+		sched.Run(nil)
+	})
+
+	AfterEach(func() {
+		sched.Cancel()
+		sched.Wait()
+	})
+
+	schedule := func(defs ...scheduler.JobDefinition) string {
+		for _, d := range defs {
+			Must(sched.ScheduleDefinition(d))
+			// enforce creation order of job ids.
+			time.Sleep(10 * time.Millisecond)
+		}
+		MustBeSuccessful(sched.Shutdown(context.Background()))
+		return buf.String()
+	}
+
+	It("emits in creation order", func() {
+		out := schedule(
+			scheduler.DefineJob("slow", output(1, 200*time.Millisecond, nil)),
+			scheduler.DefineJob("fast", output(1, 0, nil)),
+		)
+		Expect(out).To(Equal(`- JOB slow[1] done
+  line 1
+- JOB fast[2] done
+  line 1
+`))
+	})
+
+	It("emits in completion order", func() {
+		ext.SetOrder(buffered.COMPLETION_ORDER)
+		out := schedule(
+			scheduler.DefineJob("slow", output(1, 200*time.Millisecond, nil)),
+			scheduler.DefineJob("fast", output(1, 0, nil)),
+		)
+		Expect(out).To(Equal(`- JOB fast[2] done
+  line 1
+- JOB slow[1] done
+  line 1
+`))
+	})
+
+	It("emits failed jobs, only", func() {
+		ext.SetFailedOnly()
+		out := schedule(
+			scheduler.DefineJob("ok", output(1, 0, nil)),
+			scheduler.DefineJob("failed", output(1, 0, fmt.Errorf("failed"))),
+		)
+		Expect(out).To(Equal(`- JOB failed[2] failed
+  line 1
+`))
+	})
+
+	Context("truncation", func() {
+		It("keeps head and tail", func() {
+			ext.SetMaxLines(1, 2)
+			out := schedule(scheduler.DefineJob("job", output(5, 0, nil)))
+			Expect(out).To(Equal(`- JOB job[1] done
+  line 1
+  ... 2 lines skipped ...
+  line 4
+  line 5
+`))
+		})
+
+		It("keeps head", func() {
+			ext.SetMaxLines(2, 0)
+			out := schedule(scheduler.DefineJob("job", output(5, 0, nil)))
+			Expect(out).To(Equal(`- JOB job[1] done
+  line 1
+  line 2
+  ... 3 lines skipped ...
+`))
+		})
+
+		It("keeps tail", func() {
+			ext.SetMaxLines(0, 1)
+			out := schedule(scheduler.DefineJob("job", output(5, 0, nil)))
+			Expect(out).To(Equal(`- JOB job[1] done
+  ... 4 lines skipped ...
+  line 5
+`))
+		})
+
+		It("keeps short output", func() {
+			ext.SetMaxLines(2, 2)
+			out := schedule(scheduler.DefineJob("job", output(4, 0, nil)))
+			Expect(out).To(Equal(`- JOB job[1] done
+  line 1
+  line 2
+  line 3
+  line 4
+`))
+		})
+
+		It("rejects negative limits", func() {
+			Expect(func() { ext.SetMaxLines(-1, 0) }).To(Panic())
+			Expect(func() { ext.SetMaxLines(0, -1) }).To(Panic())
+		})
+	})
+
+	Context("prefixes", func() {
+		It("prefixes job ids", func() {
+			ext.SetJobIdPrefix()
+			out := schedule(scheduler.DefineJob("job", output(2, 0, nil)))
+			Expect(out).To(Equal(`- JOB job[1] done
+  job[1]: line 1
+  job[1]: line 2
+`))
+		})
+
+		It("prefixes timestamps", func() {
+			ext.SetTimestamps("15:04")
+			out := schedule(scheduler.DefineJob("job", output(1, 0, nil)))
+			Expect(out).To(MatchRegexp(`^- JOB job\[1\] done\n  [0-9]{2}:[0-9]{2} line 1\n$`))
+		})
+
+		It("indents child jobs", func() {
+			out := schedule(scheduler.DefineJob("parent", scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+				fmt.Fprintf(ctx, "parent\n")
+				child := Must(ctx.Scheduler().ScheduleDefinition(scheduler.DefineJob("child", output(1, 0, nil)), ctx.Job()))
+				child.Wait()
+				return nil, nil
+			})))
+			Expect(out).To(Equal(`- JOB parent[1] done
+  parent
+  - JOB child[2] done
+    line 1
+`))
+		})
+	})
+})
@@ -100,6 +100,9 @@ func (e *Extension) JobExtension(id string, def scheduler.JobDefinition, parent
 }
 
 func (e *Extension) Close() error {
+	if e.nested != nil {
+		return e.nested.Close()
+	}
 	return nil
 }
 
@@ -142,6 +145,24 @@ func (e *JobExtension) SetState(state scheduler.State) {
 	}
 }
 
+func (e *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	if e.nested != nil {
+		e.nested.HandleJobEvent(evt)
+	}
+}
+
+func (e *JobExtension) Heartbeat(info any) {
+	if e.nested != nil {
+		e.nested.Heartbeat(info)
+	}
+}
+
+func (e *JobExtension) Progress(info scheduler.ProgressInfo) {
+	if e.nested != nil {
+		e.nested.Progress(info)
+	}
+}
+
 func (e *JobExtension) Close() error {
 	if e.nested != nil {
 		return e.nested.Close()
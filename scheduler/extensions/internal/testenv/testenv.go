@@ -0,0 +1,62 @@
+// Package testenv provides the scheduler environment
+// shared by the tests of the extensions.
+package testenv
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+)
+
+// Scheduler creates and runs a scheduler using the given extension
+// and number of processors. The scheduler is cancelled
+// after the current spec.
+func Scheduler(ext scheduler.Extension, n int) scheduler.Scheduler {
+	sched := scheduler.New()
+	if ext != nil {
+		sched.SetExtension(ext)
+	}
+	sched.AddProcessor(n)
+	sched.Run(nil)
+	DeferCleanup(func() {
+		sched.Cancel()
+		sched.Wait()
+	})
+	return sched
+}
+
+// Execute schedules the given job definitions and shuts
+// down the scheduler, which closes the extension.
+func Execute(sched scheduler.Scheduler, defs ...scheduler.JobDefinition) {
+	Must(sched.ScheduleDefinitions(defs...))
+	MustBeSuccessful(sched.Shutdown(context.Background()))
+}
+
+// Output returns a runner writing the given output
+// and finishing with the given error.
+func Output(out string, err error) scheduler.Runner {
+	return scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+		fmt.Fprint(ctx, out)
+		return nil, err
+	})
+}
+
+// Parent returns a runner writing the given output and
+// executing the given child jobs one after the other.
+func Parent(out string, children ...scheduler.JobDefinition) scheduler.Runner {
+	return scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+		fmt.Fprint(ctx, out)
+		for _, c := range children {
+			job, err := ctx.Scheduler().ScheduleDefinition(c, ctx.Job())
+			if err != nil {
+				return nil, err
+			}
+			job.Wait()
+		}
+		return nil, nil
+	})
+}
@@ -0,0 +1,244 @@
+package jsonlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions"
+)
+
+const TYPE = "jsonlog"
+
+const (
+	// RECORD_EVENT is used for records describing a job event.
+	RECORD_EVENT = "event"
+	// RECORD_OUTPUT is used for records describing an output line of a job.
+	RECORD_OUTPUT = "output"
+	// RECORD_PROGRESS is used for records describing the progress of a job.
+	RECORD_PROGRESS = "progress"
+)
+
+// Record is the JSON representation of a single line
+// written by the extension.
+type Record struct {
+	Type     string             `json:"type"`
+	Time     time.Time          `json:"time"`
+	Job      string             `json:"job"`
+	Name     string             `json:"name,omitempty"`
+	Parent   string             `json:"parent,omitempty"`
+	Priority scheduler.Priority `json:"priority,omitempty"`
+	State    scheduler.State    `json:"state,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Timeline scheduler.Timeline `json:"timeline,omitempty"`
+	Line     string             `json:"line,omitempty"`
+	Progress *Progress          `json:"progress,omitempty"`
+}
+
+// Progress is the JSON representation of the
+// progress of a job.
+type Progress struct {
+	Total   int    `json:"total,omitempty"`
+	Current int    `json:"current"`
+	Message string `json:"message,omitempty"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type Extension struct {
+	extensions.Extension
+	lock      sync.Mutex
+	scheduler scheduler.Scheduler
+	encoder   *json.Encoder
+	closer    io.Closer
+	err       error
+}
+
+var _ scheduler.Extension = (*Extension)(nil)
+
+// New creates an extension writing job events and
+// job output as JSON lines to the given writer.
+// The output of a job is additionally forwarded to the
+// writer of the nested extension, if present.
+func New(w io.Writer, nested ...scheduler.Extension) *Extension {
+	e := &Extension{encoder: json.NewEncoder(w)}
+	e.Extension = extensions.NewExtension(e, TYPE, nested...)
+	return e
+}
+
+// NewForFile creates an extension writing to the given file.
+// The file is closed when the extension is closed.
+func NewForFile(path string, nested ...scheduler.Extension) (*Extension, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	e := &Extension{encoder: json.NewEncoder(f), closer: f}
+	e.Extension = extensions.NewExtension(e, TYPE, nested...)
+	return e, nil
+}
+
+func (e *Extension) Setup(s scheduler.Scheduler) error {
+	e.scheduler = s
+	return e.Extension.Setup(s)
+}
+
+func (e *Extension) JobExtension(id string, jd scheduler.JobDefinition, parent scheduler.Job) (scheduler.JobExtension, error) {
+	var err error
+
+	j := &JobExtension{
+		ext:      e,
+		id:       id,
+		name:     jd.GetName(),
+		priority: jd.GetPriority(),
+	}
+	if parent != nil {
+		j.parent = parent.GetId()
+	}
+	j.JobExtension, err = extensions.NewJobExtension(j, TYPE, id, jd, e.Extension)
+	if err != nil {
+		return nil, err
+	}
+	j.writer = &lineWriter{job: j, nested: j.JobExtension.Writer()}
+	return j, nil
+}
+
+// Close closes the file of the extension, if created by
+// NewForFile. It returns the first error writing a record.
+func (e *Extension) Close() error {
+	var err errors.ErrorList
+	e.lock.Lock()
+	err.Add(e.err)
+	e.lock.Unlock()
+	if e.closer != nil {
+		err.Add(e.closer.Close())
+	}
+	err.Add(e.Extension.Close())
+	return err.Result()
+}
+
+func (e *Extension) write(r *Record) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if err := e.encoder.Encode(r); err != nil && e.err == nil {
+		e.err = err
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func GetExtension(job scheduler.Job) *JobExtension {
+	return generics.Cast[*JobExtension](job.GetExtension(TYPE))
+}
+
+type JobExtension struct {
+	extensions.JobExtension
+	ext      *Extension
+	id       string
+	name     string
+	parent   string
+	priority scheduler.Priority
+	writer   *lineWriter
+}
+
+var _ scheduler.JobExtension = (*JobExtension)(nil)
+
+func (j *JobExtension) Writer() io.Writer {
+	return j.writer
+}
+
+func (j *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	r := &Record{
+		Type:     RECORD_EVENT,
+		Time:     evt.GetTime(),
+		Job:      j.id,
+		Name:     j.name,
+		Parent:   j.parent,
+		Priority: j.priority,
+		State:    evt.GetState(),
+	}
+	if scheduler.IsFinished(evt.GetState()) {
+		if _, err := evt.GetJob().GetResult(); err != nil {
+			r.Error = err.Error()
+		}
+		r.Timeline = evt.GetTimeline()
+	}
+	j.ext.write(r)
+	j.JobExtension.HandleJobEvent(evt)
+}
+
+// Progress records the progress of the job.
+func (j *JobExtension) Progress(info scheduler.ProgressInfo) {
+	j.ext.write(&Record{
+		Type: RECORD_PROGRESS,
+		Time: time.Now(),
+		Job:  j.id,
+		Progress: &Progress{
+			Total:   info.Total,
+			Current: info.Current,
+			Message: info.Message,
+		},
+	})
+	j.JobExtension.Progress(info)
+}
+
+func (j *JobExtension) Close() error {
+	j.writer.flush()
+	return j.JobExtension.Close()
+}
+
+func (j *JobExtension) output(line string) {
+	j.ext.write(&Record{
+		Type: RECORD_OUTPUT,
+		Time: time.Now(),
+		Job:  j.id,
+		Line: line,
+	})
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// lineWriter splits the job output into lines
+// and forwards it to the nested writer.
+type lineWriter struct {
+	lock   sync.Mutex
+	job    *JobExtension
+	nested io.Writer
+	buf    bytes.Buffer
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.buf.Next(i + 1))
+		w.job.output(line[:i])
+	}
+	if w.nested != nil {
+		return w.nested.Write(p)
+	}
+	return len(p), nil
+}
+
+func (w *lineWriter) flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.job.output(w.buf.String())
+		w.buf.Reset()
+	}
+}
@@ -0,0 +1,131 @@
+package jsonlog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/buffered"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/internal/testenv"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/jsonlog"
+)
+
+// records decodes the JSON lines and clears
+// the time dependent fields.
+func records(data []byte) []jsonlog.Record {
+	var list []jsonlog.Record
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var r jsonlog.Record
+		MustBeSuccessful(dec.Decode(&r))
+		Expect(r.Time.IsZero()).To(BeFalse())
+		r.Time = time.Time{}
+		if r.Timeline != nil {
+			Expect(r.Timeline[len(r.Timeline)-1].State).To(Equal(r.State))
+			r.Timeline = nil
+		}
+		list = append(list, r)
+	}
+	return list
+}
+
+// failingWriter rejects all writes.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, fmt.Errorf("write failed")
+}
+
+var _ = Describe("JSON Log Test Environment", func() {
+	It("writes events and output", func() {
+		buf := &bytes.Buffer{}
+		nested := &bytes.Buffer{}
+		sched := testenv.Scheduler(jsonlog.New(buf, buffered.New(nested)), 1)
+
+		testenv.Execute(sched, scheduler.DefineJob("job", testenv.Output("line 1\npartial", fmt.Errorf("failed"))).SetPriority(5))
+
+		Expect(records(buf.Bytes())).To(ConsistOf(
+			jsonlog.Record{Type: jsonlog.RECORD_EVENT, Job: "job[1]", Name: "job", Priority: 5, State: scheduler.INITIAL},
+			jsonlog.Record{Type: jsonlog.RECORD_EVENT, Job: "job[1]", Name: "job", Priority: 5, State: scheduler.PENDING},
+			jsonlog.Record{Type: jsonlog.RECORD_EVENT, Job: "job[1]", Name: "job", Priority: 5, State: scheduler.RUNNING},
+			jsonlog.Record{Type: jsonlog.RECORD_OUTPUT, Job: "job[1]", Line: "line 1"},
+			jsonlog.Record{Type: jsonlog.RECORD_EVENT, Job: "job[1]", Name: "job", Priority: 5, State: scheduler.FAILED, Error: "failed"},
+			jsonlog.Record{Type: jsonlog.RECORD_OUTPUT, Job: "job[1]", Line: "partial"},
+		))
+		Expect(nested.String()).To(Equal("- JOB job[1] failed\n  line 1\n  partial\n"))
+	})
+
+	It("records progress", func() {
+		buf := &bytes.Buffer{}
+		sched := testenv.Scheduler(jsonlog.New(buf), 1)
+
+		testenv.Execute(sched, scheduler.DefineJob("job", scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			p := ctx.Progress()
+			p.SetTotal(2)
+			p.SetMessage("working")
+			p.Complete()
+			return nil, nil
+		})))
+
+		var progress []jsonlog.Progress
+		for _, r := range records(buf.Bytes()) {
+			if r.Type == jsonlog.RECORD_PROGRESS {
+				Expect(r.Job).To(Equal("job[1]"))
+				progress = append(progress, *r.Progress)
+			}
+		}
+		Expect(progress).To(Equal([]jsonlog.Progress{
+			{Total: 2},
+			{Total: 2, Message: "working"},
+			{Total: 2, Current: 2, Message: "working"},
+		}))
+	})
+
+	It("reports write errors on close", func() {
+		sched := testenv.Scheduler(jsonlog.New(failingWriter{}), 1)
+
+		Must(sched.ScheduleDefinitions(scheduler.DefineJob("job", testenv.Output("line\n", nil))))
+		Expect(sched.Shutdown(context.Background())).To(MatchError(ContainSubstring("write failed")))
+	})
+
+	It("records parent jobs", func() {
+		buf := &bytes.Buffer{}
+		sched := testenv.Scheduler(jsonlog.New(buf), 2)
+
+		testenv.Execute(sched, scheduler.DefineJob("parent", testenv.Parent("", scheduler.DefineJob("child", testenv.Output("", nil)))))
+
+		var child []jsonlog.Record
+		for _, r := range records(buf.Bytes()) {
+			if r.Name == "child" {
+				child = append(child, r)
+			}
+		}
+		Expect(child).To(HaveLen(4))
+		for _, r := range child {
+			Expect(r.Job).To(Equal("child[2]"))
+			Expect(r.Parent).To(Equal("parent[1]"))
+		}
+		Expect(child).To(ContainElement(HaveField("State", scheduler.DONE)))
+	})
+
+	It("writes to file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "log.json")
+		sched := testenv.Scheduler(Must(jsonlog.NewForFile(path)), 1)
+
+		testenv.Execute(sched, scheduler.DefineJob("job", testenv.Output("line\n", nil)))
+
+		list := records(Must(os.ReadFile(path)))
+		Expect(list).To(HaveLen(5))
+		Expect(list).To(ContainElement(jsonlog.Record{Type: jsonlog.RECORD_OUTPUT, Job: "job[1]", Line: "line"}))
+		Expect(list).To(ContainElement(HaveField("State", scheduler.DONE)))
+	})
+})
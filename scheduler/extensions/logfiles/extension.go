@@ -0,0 +1,222 @@
+package logfiles
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions"
+)
+
+const TYPE = "logfiles"
+
+// INDEX is the name of the index file written to the log directory
+// when the extension is closed.
+const INDEX = "index.json"
+
+// IndexEntry describes the log file of a job in the index file.
+type IndexEntry struct {
+	Job    string          `json:"job"`
+	Parent string          `json:"parent,omitempty"`
+	File   string          `json:"file"`
+	State  scheduler.State `json:"state"`
+	Error  string          `json:"error,omitempty"`
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type Extension struct {
+	extensions.Extension
+	lock      sync.Mutex
+	scheduler scheduler.Scheduler
+	dir       string
+	maxSize   int64
+	maxFiles  int
+	index     []*IndexEntry
+}
+
+var _ scheduler.Extension = (*Extension)(nil)
+
+// New creates an extension writing the output of every job
+// into a separate log file in the given directory.
+// The log files of sub jobs are placed in a directory
+// named after the parent job. The output is additionally
+// forwarded to the writer of the nested extension, if present.
+func New(dir string, nested ...scheduler.Extension) *Extension {
+	e := &Extension{dir: dir}
+	e.Extension = extensions.NewExtension(e, TYPE, nested...)
+	return e
+}
+
+// SetMaxSize sets the maximum size of a log file. If the size
+// is exceeded, the file is rotated. 0 disables the rotation.
+func (e *Extension) SetMaxSize(size int64) *Extension {
+	e.maxSize = size
+	return e
+}
+
+// SetMaxFiles sets the number of rotated files kept
+// for a job in addition to the actual log file.
+func (e *Extension) SetMaxFiles(n int) *Extension {
+	e.maxFiles = n
+	return e
+}
+
+func (e *Extension) Setup(s scheduler.Scheduler) error {
+	e.scheduler = s
+	return e.Extension.Setup(s)
+}
+
+func (e *Extension) JobExtension(id string, jd scheduler.JobDefinition, parent scheduler.Job) (scheduler.JobExtension, error) {
+	var err error
+
+	dir := e.dir
+	entry := &IndexEntry{Job: id, State: scheduler.INITIAL}
+	if parent != nil {
+		entry.Parent = parent.GetId()
+		p := extensions.GetJobExtension[*JobExtension](parent, TYPE)
+		if p != nil {
+			dir = strings.TrimSuffix(p.path, ".log")
+		}
+	}
+
+	path := filepath.Join(dir, FileName(id)+".log")
+	err = os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+	f, err := newRotatingFile(path, e.maxSize, e.maxFiles)
+	if err != nil {
+		return nil, err
+	}
+
+	j := &JobExtension{
+		ext:   e,
+		path:  path,
+		file:  f,
+		entry: entry,
+	}
+	j.JobExtension, err = extensions.NewJobExtension(j, TYPE, id, jd, e.Extension)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if w := j.JobExtension.Writer(); w != nil {
+		j.writer = io.MultiWriter(f, w)
+	} else {
+		j.writer = f
+	}
+
+	entry.File, err = filepath.Rel(e.dir, path)
+	if err != nil {
+		entry.File = path
+	}
+
+	e.lock.Lock()
+	e.index = append(e.index, entry)
+	e.lock.Unlock()
+	return j, nil
+}
+
+// Close writes the index file describing the
+// final states of the jobs.
+func (e *Extension) Close() error {
+	var err errors.ErrorList
+	err.Add(e.writeIndex())
+	err.Add(e.Extension.Close())
+	return err.Result()
+}
+
+func (e *Extension) update(entry *IndexEntry, state scheduler.State, msg string) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	// events may be delivered out of order, but
+	// a final state is never left again.
+	if scheduler.IsFinished(entry.State) {
+		return
+	}
+	entry.State = state
+	entry.Error = msg
+}
+
+func (e *Extension) writeIndex() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	data, err := json.MarshalIndent(e.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(e.dir, INDEX), data, 0o644)
+}
+
+// FileName maps a job id to a file name.
+func FileName(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '.', r == '_', r == '-':
+			return r
+		case r == '[':
+			return '-'
+		case r == ']':
+			return -1
+		default:
+			return '_'
+		}
+	}, id)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func GetExtension(job scheduler.Job) *JobExtension {
+	return generics.Cast[*JobExtension](job.GetExtension(TYPE))
+}
+
+type JobExtension struct {
+	extensions.JobExtension
+	ext    *Extension
+	path   string
+	file   *rotatingFile
+	entry  *IndexEntry
+	writer io.Writer
+}
+
+var _ scheduler.JobExtension = (*JobExtension)(nil)
+
+// GetPath returns the path of the actual log file of the job.
+func (j *JobExtension) GetPath() string {
+	return j.path
+}
+
+func (j *JobExtension) Writer() io.Writer {
+	return j.writer
+}
+
+func (j *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	if evt.GetState() != scheduler.STALLED {
+		msg := ""
+		if scheduler.IsFailed(evt.GetState()) {
+			if _, err := evt.GetJob().GetResult(); err != nil {
+				msg = err.Error()
+			}
+		}
+		j.ext.update(j.entry, evt.GetState(), msg)
+	}
+	j.JobExtension.HandleJobEvent(evt)
+}
+
+func (j *JobExtension) Close() error {
+	var err errors.ErrorList
+	err.Add(j.file.Close())
+	err.Add(j.JobExtension.Close())
+	return err.Result()
+}
@@ -0,0 +1,104 @@
+package logfiles_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/internal/testenv"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/logfiles"
+)
+
+func lines(n int) scheduler.Runner {
+	return scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+		for i := 1; i <= n; i++ {
+			fmt.Fprintf(ctx, "line %d\n", i)
+		}
+		return nil, nil
+	})
+}
+
+var _ = Describe("Log Files Test Environment", func() {
+	var dir string
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	run := func(ext *logfiles.Extension, defs ...scheduler.JobDefinition) {
+		testenv.Execute(testenv.Scheduler(ext, 2), defs...)
+	}
+
+	file := func(path ...string) string {
+		return string(Must(os.ReadFile(filepath.Join(append([]string{dir}, path...)...))))
+	}
+
+	index := func() []logfiles.IndexEntry {
+		var list []logfiles.IndexEntry
+		MustBeSuccessful(json.Unmarshal([]byte(file(logfiles.INDEX)), &list))
+		return list
+	}
+
+	It("maps job ids to file names", func() {
+		Expect(logfiles.FileName("job[1]")).To(Equal("job-1"))
+		Expect(logfiles.FileName("a b/c.d_e[12]")).To(Equal("a_b_c.d_e-12"))
+	})
+
+	It("writes log files following the job hierarchy", func() {
+		run(logfiles.New(dir), scheduler.DefineJob("parent", testenv.Parent("parent\n",
+			scheduler.DefineJob("child", testenv.Output("child\n", fmt.Errorf("failed"))))))
+
+		Expect(file("parent-1.log")).To(Equal("parent\n"))
+		Expect(file("parent-1", "child-2.log")).To(Equal("child\n"))
+		Expect(index()).To(Equal([]logfiles.IndexEntry{
+			{Job: "parent[1]", File: "parent-1.log", State: scheduler.DONE},
+			{Job: "child[2]", Parent: "parent[1]", File: filepath.Join("parent-1", "child-2.log"), State: scheduler.FAILED, Error: "failed"},
+		}))
+	})
+
+	It("writes index on close", func() {
+		sched := testenv.Scheduler(logfiles.New(dir), 1)
+		job := Must(sched.ScheduleDefinition(scheduler.DefineJob("job", lines(1))))
+		job.Wait()
+		Expect(filepath.Join(dir, logfiles.INDEX)).NotTo(BeAnExistingFile())
+
+		MustBeSuccessful(sched.Shutdown(context.Background()))
+		Expect(index()).To(Equal([]logfiles.IndexEntry{
+			{Job: "job[1]", File: "job-1.log", State: scheduler.DONE},
+		}))
+	})
+
+	It("reports index errors on close", func() {
+		sched := testenv.Scheduler(logfiles.New(dir), 1)
+		Must(sched.ScheduleDefinition(scheduler.DefineJob("job", lines(1)))).Wait()
+		MustBeSuccessful(os.Mkdir(filepath.Join(dir, logfiles.INDEX), 0o755))
+
+		Expect(sched.Shutdown(context.Background())).To(HaveOccurred())
+	})
+
+	It("rotates log files", func() {
+		run(logfiles.New(dir).SetMaxSize(10).SetMaxFiles(2), scheduler.DefineJob("job", lines(5)))
+
+		Expect(file("job-1.log")).To(Equal("line 5\n"))
+		Expect(file("job-1.log.1")).To(Equal("line 4\n"))
+		Expect(file("job-1.log.2")).To(Equal("line 3\n"))
+		Expect(filepath.Join(dir, "job-1.log.3")).NotTo(BeAnExistingFile())
+		Expect(index()).To(Equal([]logfiles.IndexEntry{
+			{Job: "job[1]", File: "job-1.log", State: scheduler.DONE},
+		}))
+	})
+
+	It("keeps size limited log file without rotated files", func() {
+		run(logfiles.New(dir).SetMaxSize(16), scheduler.DefineJob("job", lines(5)))
+
+		Expect(file("job-1.log")).To(Equal("line 5\n"))
+		Expect(filepath.Join(dir, "job-1.log.1")).NotTo(BeAnExistingFile())
+	})
+})
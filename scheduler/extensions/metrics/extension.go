@@ -0,0 +1,227 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions"
+)
+
+const TYPE = "metrics"
+
+// DEFAULT_PREFIX is the default name prefix used for
+// the exposed metrics.
+const DEFAULT_PREFIX = "jobscheduler"
+
+type Extension struct {
+	extensions.Extension
+	lock      sync.Mutex
+	scheduler scheduler.Scheduler
+	prefix    string
+	buckets   []float64
+
+	jobs        map[string]uint64
+	stalled     uint64
+	queueTime   *Histogram
+	runTime     *Histogram
+	blockedTime *Histogram
+	states      map[scheduler.State]int
+	processors  int
+}
+
+var _ scheduler.Extension = (*Extension)(nil)
+var _ http.Handler = (*Extension)(nil)
+
+// New creates an extension collecting metrics about
+// the jobs and processors of a scheduler.
+// The extension is an http.Handler serving the metrics
+// in the Prometheus text format.
+func New(nested ...scheduler.Extension) *Extension {
+	e := &Extension{
+		prefix:  DEFAULT_PREFIX,
+		buckets: DEFAULT_BUCKETS,
+		jobs:    map[string]uint64{},
+		states:  map[scheduler.State]int{},
+	}
+	e.queueTime = newHistogram(e.buckets)
+	e.runTime = newHistogram(e.buckets)
+	e.blockedTime = newHistogram(e.buckets)
+	e.Extension = extensions.NewExtension(e, TYPE, nested...)
+	return e
+}
+
+// SetPrefix sets the name prefix used for the exposed metrics.
+func (e *Extension) SetPrefix(p string) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.prefix = p
+	return e
+}
+
+// SetBuckets sets the upper bounds (in seconds) of the
+// duration histograms. Already collected observations
+// are reset.
+func (e *Extension) SetBuckets(buckets ...float64) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.buckets = slices.Sorted(slices.Values(buckets))
+	e.queueTime = newHistogram(e.buckets)
+	e.runTime = newHistogram(e.buckets)
+	e.blockedTime = newHistogram(e.buckets)
+	return e
+}
+
+func (e *Extension) Setup(s scheduler.Scheduler) error {
+	e.lock.Lock()
+	if e.scheduler != nil {
+		e.scheduler.UnregisterHandler(e)
+	}
+	e.scheduler = s
+	e.processors = s.GetProcessorCount()
+	e.lock.Unlock()
+
+	s.RegisterHandler(e)
+	return e.Extension.Setup(s)
+}
+
+func (e *Extension) HandleSchedulerEvent(evt scheduler.SchedulerEvent) {
+	if p, ok := evt.(*scheduler.ProcessorEvent); ok {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		e.processors = p.Count
+	}
+}
+
+func (e *Extension) JobExtension(id string, jd scheduler.JobDefinition, parent scheduler.Job) (scheduler.JobExtension, error) {
+	var err error
+
+	j := &JobExtension{ext: e}
+	j.JobExtension, err = extensions.NewJobExtension(j, TYPE, id, jd, e.Extension)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (e *Extension) Close() error {
+	e.lock.Lock()
+	s := e.scheduler
+	e.lock.Unlock()
+	if s != nil {
+		s.UnregisterHandler(e)
+	}
+	return e.Extension.Close()
+}
+
+// Get returns a snapshot of the actual metrics.
+func (e *Extension) Get() *Snapshot {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	jobs := map[string]uint64{}
+	for k, v := range e.jobs {
+		jobs[k] = v
+	}
+	return &Snapshot{
+		Jobs:        jobs,
+		Stalled:     e.stalled,
+		QueueTime:   e.queueTime.copy(),
+		RunTime:     e.runTime.copy(),
+		BlockedTime: e.blockedTime.copy(),
+		Running:     e.states[scheduler.RUNNING],
+		Pending:     e.states[scheduler.PENDING] + e.states[scheduler.READY],
+		Blocked:     e.states[scheduler.BLOCKED],
+		Processors:  e.processors,
+	}
+}
+
+// ServeHTTP serves the actual metrics in the Prometheus text format.
+func (e *Extension) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e.lock.Lock()
+	prefix := e.prefix
+	e.lock.Unlock()
+
+	var buf bytes.Buffer
+	err := e.Get().WriteTo(&buf, prefix)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Write(buf.Bytes())
+}
+
+func (e *Extension) transition(old, state scheduler.State) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if old != "" {
+		e.states[old]--
+	}
+	if state != "" {
+		e.states[state]++
+	}
+}
+
+func (e *Extension) finished(evt scheduler.JobEvent) {
+	t := evt.GetTimeline()
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.jobs[string(evt.GetState())]++
+	e.queueTime.observe(t.QueueTime())
+	e.runTime.observe(t.RunTime())
+	e.blockedTime.observe(t.BlockedTime())
+}
+
+func (e *Extension) stall() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.stalled++
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func GetExtension(job scheduler.Job) *JobExtension {
+	return generics.Cast[*JobExtension](job.GetExtension(TYPE))
+}
+
+type JobExtension struct {
+	extensions.JobExtension
+	ext   *Extension
+	state scheduler.State
+}
+
+var _ scheduler.JobExtension = (*JobExtension)(nil)
+
+func (j *JobExtension) SetState(state scheduler.State) {
+	if !scheduler.IsFinished(state) {
+		j.ext.transition(j.state, state)
+		j.state = state
+	} else if j.state != "" {
+		j.ext.transition(j.state, "")
+		j.state = ""
+	}
+	j.JobExtension.SetState(state)
+}
+
+// GetMetrics returns the extension the job belongs to.
+func (j *JobExtension) GetMetrics() *Extension {
+	return j.ext
+}
+
+func (j *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	switch {
+	case evt.GetState() == scheduler.STALLED:
+		j.ext.stall()
+	case scheduler.IsFinished(evt.GetState()):
+		j.ext.finished(evt)
+	}
+	j.JobExtension.HandleJobEvent(evt)
+}
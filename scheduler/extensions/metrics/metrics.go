@@ -0,0 +1,171 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DEFAULT_BUCKETS are the upper bounds (in seconds) used for
+// the duration histograms, if not configured otherwise.
+var DEFAULT_BUCKETS = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Histogram is a snapshot of a cumulative duration histogram.
+type Histogram struct {
+	// Buckets are the upper bounds in seconds.
+	Buckets []float64
+	// Counts are the cumulative counts of observations
+	// less or equal to the corresponding bucket bound.
+	Counts []uint64
+	// Count is the total number of observations.
+	Count uint64
+	// Sum is the sum of all observations in seconds.
+	Sum float64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{
+		Buckets: buckets,
+		Counts:  make([]uint64, len(buckets)),
+	}
+}
+
+func (h *Histogram) observe(d time.Duration) {
+	v := d.Seconds()
+	for i, b := range h.Buckets {
+		if v <= b {
+			h.Counts[i]++
+		}
+	}
+	h.Count++
+	h.Sum += v
+}
+
+func (h *Histogram) copy() Histogram {
+	return Histogram{
+		Buckets: slices.Clone(h.Buckets),
+		Counts:  slices.Clone(h.Counts),
+		Count:   h.Count,
+		Sum:     h.Sum,
+	}
+}
+
+// Mean returns the average observation.
+func (h Histogram) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return time.Duration(h.Sum / float64(h.Count) * float64(time.Second))
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Snapshot is a consistent copy of all metrics
+// provided by the extension.
+type Snapshot struct {
+	// Jobs is the number of jobs per final state.
+	Jobs map[string]uint64
+	// Stalled is the number of detected stalls.
+	Stalled uint64
+
+	// QueueTime describes the time finished jobs spent
+	// waiting for a processor.
+	QueueTime Histogram
+	// RunTime describes the time finished jobs had a processor
+	// assigned.
+	RunTime Histogram
+	// BlockedTime describes the time finished jobs spent
+	// in synchronization operations.
+	BlockedTime Histogram
+
+	// Running is the number of currently running jobs.
+	Running int
+	// Pending is the number of jobs currently waiting
+	// for a processor (states PENDING and READY).
+	Pending int
+	// Blocked is the number of currently blocked jobs.
+	Blocked int
+	// Processors is the actual number of processors.
+	Processors int
+}
+
+// WriteTo writes the snapshot in the Prometheus text exposition format
+// using the given metric name prefix.
+func (s *Snapshot) WriteTo(w io.Writer, prefix string) error {
+	p := &printer{w: w, prefix: prefix}
+
+	p.header("jobs_total", "counter", "Number of jobs by final state.")
+	keys := make([]string, 0, len(s.Jobs))
+	for k := range s.Jobs {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		p.line("jobs_total", fmt.Sprintf(`{state=%q}`, k), fmt.Sprint(s.Jobs[k]))
+	}
+	p.header("jobs_stalled_total", "counter", "Number of detected job stalls.")
+	p.line("jobs_stalled_total", "", fmt.Sprint(s.Stalled))
+
+	p.histogram("job_queue_seconds", "Time finished jobs waited for a processor.", &s.QueueTime)
+	p.histogram("job_run_seconds", "Time finished jobs had a processor assigned.", &s.RunTime)
+	p.histogram("job_blocked_seconds", "Time finished jobs spent in synchronization operations.", &s.BlockedTime)
+
+	p.gauge("jobs_running", "Number of running jobs.", s.Running)
+	p.gauge("jobs_pending", "Number of jobs waiting for a processor.", s.Pending)
+	p.gauge("jobs_blocked", "Number of blocked jobs.", s.Blocked)
+	p.gauge("processors", "Number of processors.", s.Processors)
+	return p.err
+}
+
+type printer struct {
+	w      io.Writer
+	prefix string
+	err    error
+}
+
+func (p *printer) name(n string) string {
+	if p.prefix == "" {
+		return n
+	}
+	return p.prefix + "_" + n
+}
+
+func (p *printer) printf(msg string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, msg, args...)
+	}
+}
+
+func (p *printer) header(name, typ, help string) {
+	p.printf("# HELP %s %s\n", p.name(name), help)
+	p.printf("# TYPE %s %s\n", p.name(name), typ)
+}
+
+func (p *printer) line(name, labels, value string) {
+	p.printf("%s%s %s\n", p.name(name), labels, value)
+}
+
+func (p *printer) gauge(name, help string, v int) {
+	p.header(name, "gauge", help)
+	p.line(name, "", fmt.Sprint(v))
+}
+
+func (p *printer) histogram(name, help string, h *Histogram) {
+	p.header(name, "histogram", help)
+	for i, b := range h.Buckets {
+		p.line(name+"_bucket", fmt.Sprintf(`{le=%q}`, formatFloat(b)), fmt.Sprint(h.Counts[i]))
+	}
+	p.line(name+"_bucket", `{le="+Inf"}`, fmt.Sprint(h.Count))
+	p.line(name+"_sum", "", formatFloat(h.Sum))
+	p.line(name+"_count", "", fmt.Sprint(h.Count))
+}
+
+func formatFloat(f float64) string {
+	s := fmt.Sprintf("%g", f)
+	if strings.ContainsAny(s, "e") {
+		s = fmt.Sprintf("%f", f)
+	}
+	return s
+}
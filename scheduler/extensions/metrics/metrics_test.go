@@ -0,0 +1,62 @@
+package metrics_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/internal/testenv"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/metrics"
+)
+
+var _ = Describe("Metrics Test Environment", func() {
+	var sched scheduler.Scheduler
+	var ext *metrics.Extension
+
+	BeforeEach(func() {
+		ext = metrics.New()
+		sched = testenv.Scheduler(ext, 2)
+	})
+
+	It("collects job metrics", func() {
+		ok := scheduler.DefineJob("ok", testenv.Output("", nil))
+		failed := scheduler.DefineJob("failed", testenv.Output("", fmt.Errorf("failed")))
+		testenv.Execute(sched, ok, ok, failed)
+
+		m := ext.Get()
+		Expect(m.Jobs).To(Equal(map[string]uint64{"done": 2, "failed": 1}))
+		Expect(m.RunTime.Count).To(Equal(uint64(3)))
+		Expect(m.QueueTime.Count).To(Equal(uint64(3)))
+		Expect(m.Running).To(Equal(0))
+		Expect(m.Pending).To(Equal(0))
+		Expect(m.Processors).To(Equal(2))
+	})
+
+	It("serves prometheus metrics", func() {
+		job := Must(sched.ScheduleDefinition(scheduler.DefineJob("ok", testenv.Output("", nil))))
+		job.Wait()
+		sched.AddProcessor()
+
+		server := httptest.NewServer(ext)
+		defer server.Close()
+
+		resp := Must(http.Get(server.URL))
+		defer resp.Body.Close()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		text := string(Must(io.ReadAll(resp.Body)))
+
+		Expect(text).To(ContainSubstring("# TYPE jobscheduler_jobs_total counter\n"))
+		Expect(text).To(ContainSubstring(`jobscheduler_jobs_total{state="done"} 1` + "\n"))
+		Expect(text).To(ContainSubstring("# TYPE jobscheduler_job_run_seconds histogram\n"))
+		Expect(text).To(ContainSubstring(`jobscheduler_job_run_seconds_bucket{le="+Inf"} 1` + "\n"))
+		Expect(text).To(ContainSubstring("jobscheduler_job_run_seconds_count 1\n"))
+		Expect(text).To(ContainSubstring("jobscheduler_jobs_running 0\n"))
+		Expect(text).To(ContainSubstring("jobscheduler_processors 3\n"))
+	})
+})
@@ -0,0 +1,156 @@
+package multi
+
+import (
+	"io"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/jobscheduler/scheduler"
+)
+
+const TYPE = "multi"
+
+// Extension composes independent extensions (or extension chains).
+// The job output is written to the writers of all members
+// and all job operations are forwarded to all members.
+type Extension struct {
+	members []scheduler.Extension
+}
+
+var _ scheduler.Extension = (*Extension)(nil)
+
+func New(members ...scheduler.Extension) scheduler.Extension {
+	return &Extension{members: members}
+}
+
+func (e *Extension) GetExtension(typ string) scheduler.Extension {
+	if typ == TYPE {
+		return e
+	}
+	for _, m := range e.members {
+		if x := m.GetExtension(typ); x != nil {
+			return x
+		}
+	}
+	return nil
+}
+
+func (e *Extension) Members() []scheduler.Extension {
+	return e.members
+}
+
+func (e *Extension) Setup(s scheduler.Scheduler) error {
+	var err errors.ErrorList
+	for _, m := range e.members {
+		err.Add(m.Setup(s))
+	}
+	return err.Result()
+}
+
+func (e *Extension) JobExtension(id string, def scheduler.JobDefinition, parent scheduler.Job) (scheduler.JobExtension, error) {
+	j := &JobExtension{}
+	for _, m := range e.members {
+		n, err := m.JobExtension(id, def, parent)
+		if err != nil {
+			j.Close()
+			return nil, err
+		}
+		if n != nil {
+			j.members = append(j.members, n)
+		}
+	}
+
+	var writers []io.Writer
+	for _, m := range j.members {
+		if w := m.Writer(); w != nil {
+			writers = append(writers, w)
+		}
+	}
+	switch len(writers) {
+	case 0:
+	case 1:
+		j.writer = writers[0]
+	default:
+		j.writer = io.MultiWriter(writers...)
+	}
+	return j, nil
+}
+
+func (e *Extension) Close() error {
+	var err errors.ErrorList
+	for _, m := range e.members {
+		err.Add(m.Close())
+	}
+	return err.Result()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func GetExtension(job scheduler.Job) *JobExtension {
+	return generics.Cast[*JobExtension](job.GetExtension(TYPE))
+}
+
+type JobExtension struct {
+	members []scheduler.JobExtension
+	writer  io.Writer
+}
+
+var _ scheduler.JobExtension = (*JobExtension)(nil)
+
+func (j *JobExtension) GetExtension(typ string) scheduler.JobExtension {
+	if typ == TYPE {
+		return j
+	}
+	for _, m := range j.members {
+		if x := m.GetExtension(typ); x != nil {
+			return x
+		}
+	}
+	return nil
+}
+
+func (j *JobExtension) Members() []scheduler.JobExtension {
+	return j.members
+}
+
+func (j *JobExtension) Writer() io.Writer {
+	return j.writer
+}
+
+func (j *JobExtension) Start() {
+	for _, m := range j.members {
+		m.Start()
+	}
+}
+
+func (j *JobExtension) SetState(state scheduler.State) {
+	for _, m := range j.members {
+		m.SetState(state)
+	}
+}
+
+func (j *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	for _, m := range j.members {
+		m.HandleJobEvent(evt)
+	}
+}
+
+func (j *JobExtension) Heartbeat(info any) {
+	for _, m := range j.members {
+		m.Heartbeat(info)
+	}
+}
+
+func (j *JobExtension) Progress(info scheduler.ProgressInfo) {
+	for _, m := range j.members {
+		m.Progress(info)
+	}
+}
+
+func (j *JobExtension) Close() error {
+	var err errors.ErrorList
+	for _, m := range j.members {
+		err.Add(m.Close())
+	}
+	return err.Result()
+}
@@ -0,0 +1,45 @@
+package multi_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/buffered"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/internal/testenv"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/jsonlog"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/metrics"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/multi"
+)
+
+var _ = Describe("Multi Test Environment", func() {
+	It("forwards to all members", func() {
+		out := &bytes.Buffer{}
+		log := &bytes.Buffer{}
+		b := buffered.New(out)
+		m := metrics.New()
+		ext := multi.New(b, jsonlog.New(log), m)
+		sched := testenv.Scheduler(ext, 1)
+
+		job := Must(sched.ScheduleDefinition(scheduler.DefineJob("job", testenv.Output("line\n", nil))))
+		job.Wait()
+
+		Expect(ext.GetExtension(multi.TYPE)).To(BeIdenticalTo(ext))
+		Expect(ext.GetExtension(buffered.TYPE)).To(BeIdenticalTo(b))
+		Expect(ext.GetExtension(jsonlog.TYPE)).NotTo(BeNil())
+		Expect(ext.GetExtension(metrics.TYPE)).To(BeIdenticalTo(m))
+		Expect(ext.GetExtension("unknown")).To(BeNil())
+		Expect(multi.GetExtension(job).Members()).To(HaveLen(3))
+		Expect(buffered.GetExtension(job)).NotTo(BeNil())
+		Expect(jsonlog.GetExtension(job)).NotTo(BeNil())
+
+		Expect(out.String()).To(Equal("- JOB job[1] done\n  line\n"))
+		Expect(log.String()).To(ContainSubstring(`"type":"output","time":`))
+		Expect(log.String()).To(ContainSubstring(`"job":"job[1]","line":"line"}`))
+		Expect(log.String()).To(ContainSubstring(`"state":"done"`))
+		Expect(m.Get().Jobs).To(Equal(map[string]uint64{"done": 1}))
+	})
+})
@@ -2,6 +2,7 @@ package progress
 
 import (
 	"io"
+	"time"
 
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/generics"
@@ -18,6 +19,10 @@ const (
 	VAR_JOBSTATE = "jobstate"
 	VAR_JOBID    = "jobid"
 	VAR_JOBNAME  = "jobname"
+	// VAR_HEARTBEAT provides the info of the last job heartbeat.
+	VAR_HEARTBEAT = "heartbeat"
+	// VAR_MESSAGE provides the message reported by the job progress.
+	VAR_MESSAGE = "message"
 )
 
 type ExtensionDefinition struct {
@@ -57,10 +62,17 @@ type Extension struct {
 	scheduler    scheduler.Scheduler
 	pctx         ttyprogress.Context
 	defaultGroup *ttyprogress.AnonymousGroupDefinition
+	plain        *plain
 }
 
 var _ scheduler.Extension = (*Extension)(nil)
 
+// New creates an extension rendering progress indicators
+// with the given progress context, even if its output
+// is not a terminal.
+//
+// Deprecated: use NewFor, which falls back to a plain line-oriented
+// output, if the writer is not a terminal.
 func New(p ttyprogress.Context, nested ...scheduler.Extension) scheduler.Extension {
 	e := &Extension{
 		pctx:         p,
@@ -70,6 +82,37 @@ func New(p ttyprogress.Context, nested ...scheduler.Extension) scheduler.Extensi
 	return e
 }
 
+// NewPlain creates an extension using a line-oriented
+// output instead of progress indicators. It prints the state
+// transitions, the output lines prefixed with the job id and
+// the job progress at most once per interval
+// (default DEFAULT_PROGRESS_INTERVAL).
+func NewPlain(w io.Writer, interval time.Duration, nested ...scheduler.Extension) scheduler.Extension {
+	if interval <= 0 {
+		interval = DEFAULT_PROGRESS_INTERVAL
+	}
+	e := &Extension{
+		plain: &plain{writer: w, interval: interval},
+	}
+	e.Extension = extensions.NewExtension(e, TYPE, nested...)
+	return e
+}
+
+// NewFor creates an extension for the given writer.
+// If it is a terminal, progress indicators are used,
+// otherwise the plain line-oriented output is used.
+func NewFor(w io.Writer, nested ...scheduler.Extension) scheduler.Extension {
+	if IsTerminal(w) {
+		return New(ttyprogress.For(w), nested...)
+	}
+	return NewPlain(w, 0, nested...)
+}
+
+// IsPlain returns whether the plain line-oriented output is used.
+func (e *Extension) IsPlain() bool {
+	return e.plain != nil
+}
+
 func (e *Extension) Setup(s scheduler.Scheduler) error {
 	e.scheduler = s
 	return e.Extension.Setup(s)
@@ -87,12 +130,18 @@ func (e *Extension) JobExtension(jid string, jd scheduler.JobDefinition, parent
 		}
 	}
 
-	j := &JobExtension{}
+	j := &JobExtension{id: jid}
 	j.JobExtension, err = extensions.NewJobExtension(j, TYPE, jid, jd, e.Extension)
 	if err != nil {
 		return nil, err
 	}
 
+	if e.plain != nil {
+		j.plain = e.plain
+		j.writer = &plainWriter{plain: e.plain, id: jid}
+		return j, nil
+	}
+
 	var progress ttyprogress.ElementDefinition[ttyprogress.Element]
 	hideonclose := false
 	def := extensions.GetExtensionDefinition[*ExtensionDefinition](jd.GetExtension(), TYPE)
@@ -131,8 +180,13 @@ func (e *Extension) JobExtension(jid string, jd scheduler.JobDefinition, parent
 }
 
 func (e *Extension) Close() error {
-	e.pctx.Close()
-	return e.pctx.Wait(nil)
+	var err errors.ErrorList
+	if e.pctx != nil {
+		e.pctx.Close()
+		err.Add(e.pctx.Wait(nil))
+	}
+	err.Add(e.Extension.Close())
+	return err.Result()
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -143,9 +197,13 @@ func GetExtension(job scheduler.Job) *JobExtension {
 
 type JobExtension struct {
 	extensions.JobExtension
+	id       string
 	progress ttyprogress.ProgressElement
 	group    ttyprogress.AnonymousGroup
 	writer   io.WriteCloser
+
+	plain         *plain
+	plainProgress plainProgress
 }
 
 var _ scheduler.JobExtension = (*JobExtension)(nil)
@@ -163,9 +221,11 @@ func (j *JobExtension) Close() error {
 	if j.progress != nil {
 		err.Add(j.progress.Close())
 	}
-	err.Add(j.writer.Close()).
-		Add(j.group.Close()).
-		Add(j.JobExtension.Close())
+	err.Add(j.writer.Close())
+	if j.group != nil {
+		err.Add(j.group.Close())
+	}
+	err.Add(j.JobExtension.Close())
 	return err.Result()
 }
 
@@ -178,20 +238,72 @@ func (j *JobExtension) Start() {
 
 func (j *JobExtension) SetState(state scheduler.State) {
 	if j.progress != nil {
+		var s any = state
+		if m := stateMarkers[state]; m != "" {
+			s = m + " " + string(state)
+		}
 		f := stateColors[state]
 		if f == nil {
-			j.progress.SetVariable(VAR_JOBSTATE, state)
+			j.progress.SetVariable(VAR_JOBSTATE, s)
 		} else {
-			j.progress.SetVariable(VAR_JOBSTATE, f.String(state))
+			j.progress.SetVariable(VAR_JOBSTATE, f.String(s))
 		}
 	}
 	j.JobExtension.SetState(state)
 }
 
+// HandleJobEvent prints the state transitions
+// for the plain output. A partial last output line
+// is printed before the final state of the job.
+func (j *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	if j.plain != nil {
+		if w, ok := j.writer.(*plainWriter); ok && scheduler.IsFinished(evt.GetState()) {
+			w.Flush()
+		}
+		j.plain.state(j.id, evt)
+	}
+	j.JobExtension.HandleJobEvent(evt)
+}
+
+func (j *JobExtension) Heartbeat(info any) {
+	if j.progress != nil && info != nil {
+		j.progress.SetVariable(VAR_HEARTBEAT, info)
+	}
+	j.JobExtension.Heartbeat(info)
+}
+
+// Progress maps the job progress to the indicator.
+// Total and current values are only used for
+// ttyprogress.Bar indicators.
+func (j *JobExtension) Progress(info scheduler.ProgressInfo) {
+	if j.progress != nil {
+		if bar, ok := j.progress.(ttyprogress.Bar); ok {
+			if info.Total > 0 {
+				bar.SetTotal(info.Total)
+			}
+			bar.Set(info.Current)
+		}
+		j.progress.SetVariable(VAR_MESSAGE, info.Message)
+	}
+	if j.plain != nil && j.plainProgress.due(info, j.plain.interval) {
+		j.plain.progress(j.id, info)
+	}
+	j.JobExtension.Progress(info)
+}
+
 var stateColors = map[scheduler.State]ttycolors.Format{
-	scheduler.RUNNING: ttycolors.FmtBrightGreen,
-	scheduler.BLOCKED: ttycolors.FmtBrightRed,
-	scheduler.DONE:    ttycolors.FmtCyan,
-	scheduler.PENDING: ttycolors.FmtBlue,
-	scheduler.READY:   ttycolors.FmtGreen,
+	scheduler.RUNNING:   ttycolors.FmtBrightGreen,
+	scheduler.BLOCKED:   ttycolors.FmtBrightRed,
+	scheduler.DONE:      ttycolors.FmtCyan,
+	scheduler.PENDING:   ttycolors.FmtBlue,
+	scheduler.READY:     ttycolors.FmtGreen,
+	scheduler.FAILED:    ttycolors.New(ttycolors.FmtBrightRed, ttycolors.FmtBold),
+	scheduler.DISCARDED: ttycolors.New(ttycolors.FmtBlue, ttycolors.FmtItalic),
+}
+
+// stateMarkers are shown in front of final job states.
+var stateMarkers = map[scheduler.State]string{
+	scheduler.DONE:      "✔",
+	scheduler.FAILED:    "✘",
+	scheduler.DISCARDED: "⊘",
 }
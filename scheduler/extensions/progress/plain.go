@@ -0,0 +1,136 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mattn/go-isatty"
+)
+
+// DEFAULT_PROGRESS_INTERVAL is the default minimal interval
+// between two progress lines of a job printed by the plain renderer.
+const DEFAULT_PROGRESS_INTERVAL = 5 * time.Second
+
+// IsTerminal checks whether the given writer is a terminal.
+func IsTerminal(w io.Writer) bool {
+	if f, ok := w.(*os.File); ok {
+		return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+	}
+	return false
+}
+
+// plain is a line-oriented renderer used instead of
+// progress indicators, if the output is not a terminal.
+// It prints state transitions, the output of the jobs
+// prefixed by the job id and periodic progress lines.
+type plain struct {
+	lock     sync.Mutex
+	writer   io.Writer
+	interval time.Duration
+}
+
+func (p *plain) printf(msg string, args ...any) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	fmt.Fprintf(p.writer, msg, args...)
+}
+
+func (p *plain) state(id string, evt scheduler.JobEvent) {
+	state := evt.GetState()
+	if state == scheduler.INITIAL {
+		return
+	}
+	msg := string(state)
+	if m := stateMarkers[state]; m != "" {
+		msg = m + " " + msg
+	}
+	if scheduler.IsFailed(state) {
+		if _, err := evt.GetJob().GetResult(); err != nil {
+			msg += ": " + err.Error()
+		}
+	}
+	p.printf("%s %s: %s\n", evt.GetTime().Format(time.TimeOnly), id, msg)
+}
+
+func (p *plain) progress(id string, info scheduler.ProgressInfo) {
+	msg := ""
+	if info.Message != "" {
+		msg = " " + info.Message
+	}
+	if pct := info.Percent(); pct >= 0 {
+		p.printf("%s %s: %3.0f%% (%d/%d)%s\n", time.Now().Format(time.TimeOnly), id, pct, info.Current, info.Total, msg)
+	} else {
+		p.printf("%s %s: %d%s\n", time.Now().Format(time.TimeOnly), id, info.Current, msg)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// plainWriter prefixes every output line of a job
+// with the job id.
+type plainWriter struct {
+	lock  sync.Mutex
+	plain *plain
+	id    string
+	buf   bytes.Buffer
+}
+
+var _ io.WriteCloser = (*plainWriter)(nil)
+
+func (w *plainWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.buf.Write(p)
+	for {
+		i := bytes.IndexByte(w.buf.Bytes(), '\n')
+		if i < 0 {
+			break
+		}
+		line := string(w.buf.Next(i + 1))
+		w.plain.printf("%s> %s", w.id, line)
+	}
+	return len(p), nil
+}
+
+// Flush prints a pending partial last line.
+func (w *plainWriter) Flush() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.plain.printf("%s> %s\n", w.id, w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *plainWriter) Close() error {
+	w.Flush()
+	return nil
+}
+
+// plainProgress throttles the progress lines of a job.
+type plainProgress struct {
+	lock sync.Mutex
+	last time.Time
+	pct  float64
+}
+
+func (p *plainProgress) due(info scheduler.ProgressInfo, interval time.Duration) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	now := time.Now()
+	pct := info.Percent()
+	if (pct >= 100 && p.pct < 100) || now.Sub(p.last) >= interval {
+		p.last = now
+		p.pct = pct
+		return true
+	}
+	return false
+}
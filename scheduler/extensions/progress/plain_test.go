@@ -0,0 +1,142 @@
+package progress_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/progress"
+)
+
+// syncBuffer is a bytes.Buffer usable by several Go routines.
+type syncBuffer struct {
+	lock sync.Mutex
+	buf  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.buf.Write(p)
+}
+
+var timestamp = regexp.MustCompile(`(?m)^[0-9]{2}:[0-9]{2}:[0-9]{2} `)
+
+// String provides the output without timestamps.
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return timestamp.ReplaceAllString(b.buf.String(), "")
+}
+
+// Lines provides the output lines without timestamps.
+func (b *syncBuffer) Lines() []string {
+	return strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+}
+
+// inOrder checks whether the given lines appear
+// in the given order.
+func inOrder(lines []string, ordered ...string) {
+	last := -1
+	for _, l := range ordered {
+		i := slices.Index(lines, l)
+		ExpectWithOffset(1, i).To(BeNumerically(">", last), "line %q out of order", l)
+		last = i
+	}
+}
+
+var _ = Describe("Plain Test Environment", func() {
+	var sched scheduler.Scheduler
+	var buf *syncBuffer
+
+	BeforeEach(func() {
+		buf = &syncBuffer{}
+		sched = scheduler.New()
+		sched.SetExtension(progress.NewPlain(buf, time.Hour))
+		sched.AddProcessor()
+		sched.Run(nil)
+	})
+
+	AfterEach(func() {
+		sched.Cancel()
+		sched.Wait()
+	})
+
+	run := func(runner scheduler.RunnerFunc) {
+		Must(sched.ScheduleDefinition(scheduler.DefineJob("job", runner)))
+		MustBeSuccessful(sched.Shutdown(context.Background()))
+	}
+
+	It("uses plain output for non-terminals", func() {
+		Expect(progress.IsTerminal(&bytes.Buffer{})).To(BeFalse())
+		ext := progress.NewFor(&bytes.Buffer{})
+		Expect(ext.(*progress.Extension).IsPlain()).To(BeTrue())
+	})
+
+	It("prints states and prefixed output", func() {
+		run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			fmt.Fprintf(ctx, "line 1\nline 2\npartial")
+			return nil, nil
+		})
+		lines := buf.Lines()
+		Expect(lines).To(ConsistOf(
+			"job[1]: pending",
+			"job[1]: running",
+			"job[1]> line 1",
+			"job[1]> line 2",
+			"job[1]> partial",
+			"job[1]: ✔ done",
+		))
+		inOrder(lines, "job[1]> line 1", "job[1]> line 2", "job[1]> partial", "job[1]: ✔ done")
+	})
+
+	It("prints failures", func() {
+		run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			return nil, fmt.Errorf("failed")
+		})
+		Expect(buf.Lines()).To(ConsistOf(
+			"job[1]: pending",
+			"job[1]: running",
+			"job[1]: ✘ failed: failed",
+		))
+	})
+
+	It("throttles progress", func() {
+		run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			p := ctx.Progress()
+			p.SetTotal(4)
+			p.Set(1)
+			p.SetMessage("working")
+			p.Set(2)
+			p.Complete()
+			return nil, nil
+		})
+		lines := buf.Lines()
+		Expect(lines).To(ConsistOf(
+			"job[1]: pending",
+			"job[1]: running",
+			"job[1]:   0% (0/4)",
+			"job[1]: 100% (4/4) working",
+			"job[1]: ✔ done",
+		))
+		inOrder(lines, "job[1]:   0% (0/4)", "job[1]: 100% (4/4) working", "job[1]: ✔ done")
+	})
+
+	It("prints progress without total", func() {
+		run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			ctx.Progress().Set(3)
+			return nil, nil
+		})
+		Expect(buf.String()).To(ContainSubstring("job[1]: 3\n"))
+	})
+})
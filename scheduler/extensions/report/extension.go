@@ -0,0 +1,244 @@
+package report
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions"
+)
+
+const TYPE = "report"
+
+// JobInfo describes the execution of a job
+// as recorded by the extension.
+type JobInfo struct {
+	Id       string
+	Name     string
+	Parent   string
+	State    scheduler.State
+	Error    string
+	Output   string
+	Timeline scheduler.Timeline
+	Children []*JobInfo
+}
+
+type Extension struct {
+	extensions.Extension
+	lock      sync.Mutex
+	name      string
+	junit     string
+	html      string
+	maxOutput int
+
+	jobs  map[string]*JobInfo
+	order []*JobInfo
+}
+
+var _ scheduler.Extension = (*Extension)(nil)
+
+// New creates an extension recording all jobs. On Close
+// it writes a JUnit XML report and/or an HTML page with a
+// timeline of all jobs, if the corresponding files are configured.
+// The reports can be written explicitly using
+// WriteJUnit and WriteHTML.
+func New(nested ...scheduler.Extension) *Extension {
+	e := &Extension{name: "jobscheduler", jobs: map[string]*JobInfo{}}
+	e.Extension = extensions.NewExtension(e, TYPE, nested...)
+	return e
+}
+
+// SetName sets the name used for the reports.
+func (e *Extension) SetName(name string) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.name = name
+	return e
+}
+
+// SetJUnitFile sets the file the JUnit XML report is written to on Close.
+func (e *Extension) SetJUnitFile(path string) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.junit = path
+	return e
+}
+
+// SetHTMLFile sets the file the HTML report is written to on Close.
+func (e *Extension) SetHTMLFile(path string) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.html = path
+	return e
+}
+
+// SetMaxOutput limits the captured output of a job to the
+// last n bytes. 0 means unlimited.
+func (e *Extension) SetMaxOutput(n int) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.maxOutput = n
+	return e
+}
+
+func (e *Extension) JobExtension(id string, jd scheduler.JobDefinition, parent scheduler.Job) (scheduler.JobExtension, error) {
+	var err error
+
+	info := &JobInfo{
+		Id:    id,
+		Name:  jd.GetName(),
+		State: scheduler.INITIAL,
+	}
+
+	e.lock.Lock()
+	if parent != nil {
+		info.Parent = parent.GetId()
+		if p := e.jobs[info.Parent]; p != nil {
+			p.Children = append(p.Children, info)
+		}
+	}
+	e.jobs[id] = info
+	e.order = append(e.order, info)
+	max := e.maxOutput
+	e.lock.Unlock()
+
+	j := &JobExtension{ext: e, info: info}
+	j.JobExtension, err = extensions.NewJobExtension(j, TYPE, id, jd, e.Extension)
+	if err != nil {
+		return nil, err
+	}
+	j.writer = &captureWriter{max: max, nested: j.JobExtension.Writer()}
+	return j, nil
+}
+
+func (e *Extension) Close() error {
+	var err errors.ErrorList
+
+	e.lock.Lock()
+	junit, html := e.junit, e.html
+	e.lock.Unlock()
+
+	if junit != "" {
+		err.Add(writeFile(junit, e.WriteJUnit))
+	}
+	if html != "" {
+		err.Add(writeFile(html, e.WriteHTML))
+	}
+	err.Add(e.Extension.Close())
+	return err.Result()
+}
+
+// Jobs returns a copy of the recorded root jobs
+// including their sub jobs.
+func (e *Extension) Jobs() []*JobInfo {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	var result []*JobInfo
+	for _, j := range e.order {
+		if j.Parent == "" || e.jobs[j.Parent] == nil {
+			result = append(result, j.copy())
+		}
+	}
+	return result
+}
+
+func (j *JobInfo) copy() *JobInfo {
+	c := *j
+	c.Children = nil
+	for _, n := range j.Children {
+		c.Children = append(c.Children, n.copy())
+	}
+	return &c
+}
+
+func writeFile(path string, writer func(w io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = writer(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return errors.Wrapf(err, "report %s", path)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func GetExtension(job scheduler.Job) *JobExtension {
+	return generics.Cast[*JobExtension](job.GetExtension(TYPE))
+}
+
+type JobExtension struct {
+	extensions.JobExtension
+	ext    *Extension
+	info   *JobInfo
+	writer *captureWriter
+}
+
+var _ scheduler.JobExtension = (*JobExtension)(nil)
+
+func (j *JobExtension) Writer() io.Writer {
+	return j.writer
+}
+
+func (j *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	j.ext.lock.Lock()
+	// events may be delivered out of order, but
+	// a final state is never left again.
+	if evt.GetState() != scheduler.STALLED && !scheduler.IsFinished(j.info.State) {
+		j.info.State = evt.GetState()
+		j.info.Timeline = evt.GetTimeline()
+		if scheduler.IsFinished(evt.GetState()) {
+			if _, err := evt.GetJob().GetResult(); err != nil {
+				j.info.Error = err.Error()
+			}
+		}
+	}
+	j.ext.lock.Unlock()
+	j.JobExtension.HandleJobEvent(evt)
+}
+
+func (j *JobExtension) Close() error {
+	out := j.writer.String()
+	j.ext.lock.Lock()
+	j.info.Output = out
+	j.ext.lock.Unlock()
+	return j.JobExtension.Close()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// captureWriter keeps the output of a job
+// and forwards it to the nested writer.
+type captureWriter struct {
+	lock   sync.Mutex
+	max    int
+	nested io.Writer
+	buf    bytes.Buffer
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	w.buf.Write(p)
+	if w.max > 0 && w.buf.Len() > w.max {
+		w.buf.Next(w.buf.Len() - w.max)
+	}
+	w.lock.Unlock()
+
+	if w.nested != nil {
+		return w.nested.Write(p)
+	}
+	return len(p), nil
+}
+
+func (w *captureWriter) String() string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.buf.String()
+}
@@ -0,0 +1,138 @@
+package report_test
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/internal/testenv"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/report"
+)
+
+// JUnit XML structure used to check the written report.
+
+type suites struct {
+	Name     string  `xml:"name,attr"`
+	Tests    int     `xml:"tests,attr"`
+	Failures int     `xml:"failures,attr"`
+	Suites   []suite `xml:"testsuite"`
+}
+
+type suite struct {
+	Name     string     `xml:"name,attr"`
+	Tests    int        `xml:"tests,attr"`
+	Failures int        `xml:"failures,attr"`
+	Cases    []testcase `xml:"testcase"`
+	Suites   []suite    `xml:"testsuite"`
+}
+
+type testcase struct {
+	Name      string `xml:"name,attr"`
+	ClassName string `xml:"classname,attr"`
+	Failure   *struct {
+		Message string `xml:"message,attr"`
+		Type    string `xml:"type,attr"`
+	} `xml:"failure"`
+	SystemOut string `xml:"system-out"`
+}
+
+var _ = Describe("Report Test Environment", func() {
+	var ext *report.Extension
+	var dir string
+
+	run := func() {
+		dir = GinkgoT().TempDir()
+		ext.SetJUnitFile(filepath.Join(dir, "junit.xml")).
+			SetHTMLFile(filepath.Join(dir, "report.html"))
+		testenv.Execute(testenv.Scheduler(ext, 2),
+			scheduler.DefineJob("parent", testenv.Parent("parent output\n",
+				scheduler.DefineJob("child", testenv.Output("ok\n", nil)),
+				scheduler.DefineJob("child", testenv.Output("broken\n", fmt.Errorf("failed"))),
+			)),
+			scheduler.DefineJob("single", testenv.Output("", nil)),
+		)
+	}
+
+	BeforeEach(func() {
+		ext = report.New().SetName("test")
+	})
+
+	It("records jobs", func() {
+		run()
+		jobs := ext.Jobs()
+		Expect(jobs).To(HaveLen(2))
+		Expect(jobs[0].Id).To(Equal("parent[1]"))
+		Expect(jobs[0].State).To(Equal(scheduler.DONE))
+		Expect(jobs[0].Output).To(Equal("parent output\n"))
+		Expect(jobs[0].Children).To(HaveLen(2))
+		Expect(jobs[0].Children[1].Parent).To(Equal("parent[1]"))
+		Expect(jobs[0].Children[1].State).To(Equal(scheduler.FAILED))
+		Expect(jobs[0].Children[1].Error).To(Equal("failed"))
+		Expect(jobs[1].Id).To(Equal("single[2]"))
+	})
+
+	It("writes JUnit report", func() {
+		run()
+
+		var r suites
+		MustBeSuccessful(xml.Unmarshal(Must(os.ReadFile(filepath.Join(dir, "junit.xml"))), &r))
+		Expect(r.Name).To(Equal("test"))
+		Expect(r.Tests).To(Equal(4))
+		Expect(r.Failures).To(Equal(1))
+		Expect(r.Suites).To(HaveLen(1))
+
+		root := r.Suites[0]
+		Expect(root.Name).To(Equal("test"))
+		Expect(root.Tests).To(Equal(4))
+		Expect(root.Failures).To(Equal(1))
+		Expect(root.Cases).To(HaveLen(1))
+		Expect(root.Cases[0].Name).To(Equal("single[2]"))
+		Expect(root.Cases[0].ClassName).To(Equal("test"))
+		Expect(root.Cases[0].Failure).To(BeNil())
+
+		Expect(root.Suites).To(HaveLen(1))
+		sub := root.Suites[0]
+		Expect(sub.Name).To(Equal("parent[1]"))
+		Expect(sub.Tests).To(Equal(3))
+		Expect(sub.Failures).To(Equal(1))
+		Expect(sub.Cases).To(HaveLen(3))
+		Expect(sub.Cases[0].Name).To(Equal("parent[1]"))
+		Expect(sub.Cases[0].SystemOut).To(Equal("parent output\n"))
+		Expect(sub.Cases[1].Name).To(Equal("child[3]"))
+		Expect(sub.Cases[1].ClassName).To(Equal("test.parent[1]"))
+		Expect(sub.Cases[1].Failure).To(BeNil())
+		Expect(sub.Cases[2].Name).To(Equal("child[4]"))
+		Expect(sub.Cases[2].Failure).NotTo(BeNil())
+		Expect(sub.Cases[2].Failure.Message).To(Equal("failed"))
+		Expect(sub.Cases[2].Failure.Type).To(Equal("failed"))
+		Expect(sub.Cases[2].SystemOut).To(Equal("broken\n"))
+	})
+
+	It("writes HTML report", func() {
+		run()
+
+		html := string(Must(os.ReadFile(filepath.Join(dir, "report.html"))))
+		Expect(html).To(ContainSubstring("<title>test</title>"))
+		Expect(html).To(ContainSubstring(`<td style="padding-left: 0em">parent[1]</td>`))
+		Expect(html).To(ContainSubstring(`<td style="padding-left: 1em">child[4]</td>`))
+		Expect(html).To(ContainSubstring(`<summary>failed</summary><pre>broken
+</pre>`))
+		Expect(html).To(ContainSubstring(`<summary>output</summary><pre>parent output
+</pre>`))
+		Expect(html).To(MatchRegexp(`class="seg" style="left: [0-9.]+%; width: [0-9.]+%; background: #6cc36c" title="running: `))
+		Expect(html).To(ContainSubstring("done: 3"))
+		Expect(html).To(ContainSubstring("failed: 1"))
+	})
+
+	It("limits captured output", func() {
+		ext.SetMaxOutput(4)
+		run()
+		Expect(ext.Jobs()[0].Output).To(Equal("put\n"))
+	})
+})
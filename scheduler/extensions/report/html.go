@@ -0,0 +1,188 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+)
+
+// stateColors are the colors used for the states
+// in the timeline view.
+var stateColors = map[scheduler.State]string{
+	scheduler.INITIAL:   "#e0e0e0",
+	scheduler.WAITING:   "#c9c9f5",
+	scheduler.PENDING:   "#f5e6a3",
+	scheduler.READY:     "#f5d37a",
+	scheduler.RUNNING:   "#6cc36c",
+	scheduler.BLOCKED:   "#e89b5a",
+	scheduler.ZOMBIE:    "#9ecae1",
+	scheduler.DONE:      "#2e7d32",
+	scheduler.FAILED:    "#c62828",
+	scheduler.DISCARDED: "#9e9e9e",
+}
+
+var legendStates = []scheduler.State{
+	scheduler.WAITING,
+	scheduler.PENDING,
+	scheduler.READY,
+	scheduler.RUNNING,
+	scheduler.BLOCKED,
+	scheduler.ZOMBIE,
+}
+
+type htmlPage struct {
+	Name     string
+	Start    string
+	Duration string
+	Counts   map[scheduler.State]int
+	Legend   []htmlLegend
+	Rows     []htmlRow
+}
+
+type htmlLegend struct {
+	State scheduler.State
+	Color string
+}
+
+type htmlRow struct {
+	Id       string
+	Indent   int
+	State    scheduler.State
+	Color    string
+	Elapsed  string
+	Error    string
+	Output   string
+	Segments []htmlSegment
+}
+
+type htmlSegment struct {
+	Left  string
+	Width string
+	Color string
+	Title string
+}
+
+// WriteHTML writes a self-contained HTML page showing
+// the recorded jobs on a common timeline.
+func (e *Extension) WriteHTML(w io.Writer) error {
+	e.lock.Lock()
+	name := e.name
+	e.lock.Unlock()
+
+	jobs := e.Jobs()
+
+	var start, end time.Time
+	walk(jobs, 0, func(j *JobInfo, _ int) {
+		for _, t := range j.Timeline {
+			if t.State == scheduler.INITIAL {
+				continue
+			}
+			if start.IsZero() || t.Time.Before(start) {
+				start = t.Time
+			}
+			if t.Time.After(end) {
+				end = t.Time
+			}
+		}
+	})
+	total := end.Sub(start)
+
+	page := htmlPage{
+		Name:     name,
+		Duration: total.String(),
+		Counts:   map[scheduler.State]int{},
+	}
+	if !start.IsZero() {
+		page.Start = start.Format(time.RFC3339)
+	}
+	for _, s := range legendStates {
+		page.Legend = append(page.Legend, htmlLegend{s, stateColors[s]})
+	}
+
+	walk(jobs, 0, func(j *JobInfo, depth int) {
+		page.Counts[j.State]++
+		row := htmlRow{
+			Id:      j.Id,
+			Indent:  depth,
+			State:   j.State,
+			Color:   stateColors[j.State],
+			Elapsed: j.Timeline.Elapsed().Round(time.Millisecond).String(),
+			Error:   j.Error,
+			Output:  j.Output,
+		}
+		for i, t := range j.Timeline {
+			if t.State == scheduler.INITIAL || scheduler.IsFinished(t.State) {
+				continue
+			}
+			until := end
+			if i+1 < len(j.Timeline) {
+				until = j.Timeline[i+1].Time
+			}
+			row.Segments = append(row.Segments, htmlSegment{
+				Left:  percent(t.Time.Sub(start), total),
+				Width: percent(until.Sub(t.Time), total),
+				Color: stateColors[t.State],
+				Title: fmt.Sprintf("%s: %s", t.State, until.Sub(t.Time).Round(time.Microsecond)),
+			})
+		}
+		page.Rows = append(page.Rows, row)
+	})
+	return htmlTemplate.Execute(w, &page)
+}
+
+func walk(jobs []*JobInfo, depth int, f func(j *JobInfo, depth int)) {
+	for _, j := range jobs {
+		f(j, depth)
+		walk(j.Children, depth+1, f)
+	}
+}
+
+func percent(d, total time.Duration) string {
+	if total <= 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.3f%%", float64(d)*100/float64(total))
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: sans-serif; font-size: 13px; margin: 20px; }
+h1 { font-size: 18px; }
+table { border-collapse: collapse; width: 100%; }
+td { padding: 2px 6px; border-bottom: 1px solid #eee; white-space: nowrap; }
+td.bar { width: 70%; }
+.track { position: relative; height: 14px; background: #fafafa; }
+.seg { position: absolute; top: 0; height: 14px; min-width: 1px; }
+.state { color: #fff; padding: 0 4px; border-radius: 3px; }
+.legend span { display: inline-block; margin-right: 12px; }
+.legend i { display: inline-block; width: 12px; height: 12px; margin-right: 4px; vertical-align: middle; }
+pre { background: #f4f4f4; padding: 6px; white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<p>Started {{.Start}}, duration {{.Duration}}{{range $s, $c := .Counts}}, {{$s}}: {{$c}}{{end}}</p>
+<p class="legend">{{range .Legend}}<span><i style="background: {{.Color}}"></i>{{.State}}</span>{{end}}</p>
+<table>
+{{- range .Rows}}
+<tr>
+<td style="padding-left: {{.Indent}}em">{{.Id}}</td>
+<td><span class="state" style="background: {{.Color}}">{{.State}}</span></td>
+<td>{{.Elapsed}}</td>
+<td class="bar"><div class="track">{{range .Segments}}<div class="seg" style="left: {{.Left}}; width: {{.Width}}; background: {{.Color}}" title="{{.Title}}"></div>{{end}}</div></td>
+</tr>
+{{- if or .Error .Output}}
+<tr><td colspan="4"><details><summary>{{if .Error}}{{.Error}}{{else}}output{{end}}</summary><pre>{{.Output}}</pre></details></td></tr>
+{{- end}}
+{{- end}}
+</table>
+</body>
+</html>
+`))
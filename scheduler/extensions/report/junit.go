@@ -0,0 +1,160 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+)
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name      string       `xml:"name,attr"`
+	Tests     int          `xml:"tests,attr"`
+	Failures  int          `xml:"failures,attr"`
+	Skipped   int          `xml:"skipped,attr"`
+	Time      string       `xml:"time,attr"`
+	Timestamp string       `xml:"timestamp,attr,omitempty"`
+	Cases     []junitCase  `xml:"testcase"`
+	Suites    []junitSuite `xml:"testsuite"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the recorded jobs as JUnit XML report.
+// Every job is reported as test case. Jobs with sub jobs
+// are additionally reported as test suite containing the
+// test case of the job itself and the nested jobs.
+func (e *Extension) WriteJUnit(w io.Writer) error {
+	e.lock.Lock()
+	name := e.name
+	e.lock.Unlock()
+
+	root := junitSuite{Name: name}
+	var start, end time.Time
+	for _, j := range e.Jobs() {
+		addJUnit(&root, j, name)
+		if s := startTime(j.Timeline); !s.IsZero() && (start.IsZero() || s.Before(start)) {
+			start = s
+		}
+		if f := j.Timeline.Finished(); f.After(end) {
+			end = f
+		}
+	}
+	if !start.IsZero() {
+		root.Timestamp = start.Format(time.RFC3339)
+		if end.After(start) {
+			root.Time = seconds(end.Sub(start))
+		}
+	}
+	if root.Time == "" {
+		root.Time = seconds(0)
+	}
+
+	suites := junitSuites{
+		Name:     name,
+		Tests:    root.Tests,
+		Failures: root.Failures,
+		Skipped:  root.Skipped,
+		Time:     root.Time,
+		Suites:   []junitSuite{root},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(&suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func addJUnit(suite *junitSuite, j *JobInfo, class string) {
+	c := junitCase{
+		Name:      j.Id,
+		ClassName: class,
+		Time:      seconds(j.Timeline.Elapsed()),
+		SystemOut: j.Output,
+	}
+	switch {
+	case scheduler.IsFailed(j.State):
+		c.Failure = &junitFailure{Message: j.Error, Type: string(j.State), Text: j.Error}
+	case scheduler.IsDiscarded(j.State):
+		c.Skipped = &junitSkipped{Message: string(j.State)}
+	case !scheduler.IsFinished(j.State):
+		c.Failure = &junitFailure{Message: fmt.Sprintf("job not finished (%s)", j.State), Type: string(j.State)}
+	}
+
+	if len(j.Children) == 0 {
+		suite.Cases = append(suite.Cases, c)
+		suite.count(&c)
+		return
+	}
+
+	sub := junitSuite{Name: j.Id, Time: c.Time}
+	if s := startTime(j.Timeline); !s.IsZero() {
+		sub.Timestamp = s.Format(time.RFC3339)
+	}
+	sub.Cases = append(sub.Cases, c)
+	sub.count(&c)
+	for _, n := range j.Children {
+		addJUnit(&sub, n, class+"."+j.Id)
+	}
+	suite.Suites = append(suite.Suites, sub)
+	suite.Tests += sub.Tests
+	suite.Failures += sub.Failures
+	suite.Skipped += sub.Skipped
+}
+
+func (s *junitSuite) count(c *junitCase) {
+	s.Tests++
+	if c.Failure != nil {
+		s.Failures++
+	}
+	if c.Skipped != nil {
+		s.Skipped++
+	}
+}
+
+func startTime(t scheduler.Timeline) time.Time {
+	s := t.Scheduled()
+	if s.IsZero() {
+		s = t.Applied()
+	}
+	return s
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,232 @@
+package summary
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/goutils/optionutils"
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions"
+	"github.com/mandelsoft/ttycolors"
+)
+
+const TYPE = "summary"
+
+// DEFAULT_SLOWEST is the default number of slowest jobs
+// shown in the summary.
+const DEFAULT_SLOWEST = 5
+
+// JobSummary describes a finished job.
+type JobSummary struct {
+	Id       string
+	State    scheduler.State
+	Error    string
+	Timeline scheduler.Timeline
+}
+
+type Extension struct {
+	extensions.Extension
+	lock    sync.Mutex
+	writer  io.Writer
+	slowest int
+	colored bool
+
+	jobs []*JobSummary
+}
+
+var _ scheduler.Extension = (*Extension)(nil)
+
+// New creates an extension printing a summary of all
+// finished jobs to the given writer, when the extension is closed.
+func New(w io.Writer, nested ...scheduler.Extension) *Extension {
+	e := &Extension{writer: w, slowest: DEFAULT_SLOWEST}
+	e.Extension = extensions.NewExtension(e, TYPE, nested...)
+	return e
+}
+
+// SetSlowest sets the number of slowest jobs shown.
+func (e *Extension) SetSlowest(n int) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.slowest = n
+	return e
+}
+
+// SetColored enables (default) or disables the coloring of
+// job states using terminal escape sequences.
+func (e *Extension) SetColored(b ...bool) *Extension {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.colored = optionutils.BoolOption(b...)
+	return e
+}
+
+func (e *Extension) JobExtension(id string, jd scheduler.JobDefinition, parent scheduler.Job) (scheduler.JobExtension, error) {
+	var err error
+
+	j := &JobExtension{ext: e, id: id}
+	j.JobExtension, err = extensions.NewJobExtension(j, TYPE, id, jd, e.Extension)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (e *Extension) Close() error {
+	e.Print(e.writer)
+	return e.Extension.Close()
+}
+
+// Jobs returns the finished jobs in the order of completion.
+func (e *Extension) Jobs() []*JobSummary {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return slices.Clone(e.jobs)
+}
+
+func (e *Extension) finished(j *JobSummary) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.jobs = append(e.jobs, j)
+}
+
+// Print prints the summary of all jobs finished so far.
+func (e *Extension) Print(w io.Writer) {
+	e.lock.Lock()
+	slowest := e.slowest
+	colored := e.colored
+	e.lock.Unlock()
+
+	jobs := e.Jobs()
+
+	format := func(state scheduler.State) func(string) string {
+		if !colored {
+			return nil
+		}
+		f := stateColors[state]
+		if f == nil {
+			return nil
+		}
+		return func(s string) string {
+			return fmt.Sprint(f.String(s))
+		}
+	}
+
+	var start, end time.Time
+	var busy time.Duration
+	counts := map[scheduler.State]int{}
+	var failed []*JobSummary
+	for _, j := range jobs {
+		counts[j.State]++
+		busy += j.Timeline.RunTime()
+		if s := j.Timeline.Scheduled(); !s.IsZero() && (start.IsZero() || s.Before(start)) {
+			start = s
+		}
+		if f := j.Timeline.Finished(); f.After(end) {
+			end = f
+		}
+		if scheduler.IsFailed(j.State) {
+			failed = append(failed, j)
+		}
+	}
+
+	fmt.Fprintf(w, "Summary of %d jobs\n", len(jobs))
+	states := &table{header: []string{"STATE", "JOBS"}}
+	for _, s := range []scheduler.State{scheduler.DONE, scheduler.FAILED, scheduler.DISCARDED} {
+		states.add(cell{text: string(s), format: format(s)}, cell{text: fmt.Sprint(counts[s]), right: true})
+	}
+	states.print(w, "  ")
+
+	if slowest > 0 && len(jobs) > 0 {
+		sorted := slices.Clone(jobs)
+		slices.SortStableFunc(sorted, func(a, b *JobSummary) int {
+			return cmp.Compare(b.Timeline.Elapsed(), a.Timeline.Elapsed())
+		})
+		if len(sorted) > slowest {
+			sorted = sorted[:slowest]
+		}
+		fmt.Fprintf(w, "\nSlowest jobs\n")
+		slow := &table{header: []string{"JOB", "STATE", "ELAPSED", "QUEUED", "RUNNING", "BLOCKED"}}
+		for _, j := range sorted {
+			slow.add(
+				cell{text: j.Id},
+				cell{text: string(j.State), format: format(j.State)},
+				cell{text: duration(j.Timeline.Elapsed()), right: true},
+				cell{text: duration(j.Timeline.QueueTime()), right: true},
+				cell{text: duration(j.Timeline.RunTime()), right: true},
+				cell{text: duration(j.Timeline.BlockedTime()), right: true},
+			)
+		}
+		slow.print(w, "  ")
+	}
+
+	if len(failed) > 0 {
+		fmt.Fprintf(w, "\nFailed jobs\n")
+		errs := &table{header: []string{"JOB", "ERROR"}}
+		for _, j := range failed {
+			errs.add(cell{text: j.Id, format: format(j.State)}, cell{text: j.Error})
+		}
+		errs.print(w, "  ")
+	}
+
+	wall := end.Sub(start)
+	if wall < 0 {
+		wall = 0
+	}
+	parallelism := 0.0
+	if wall > 0 {
+		parallelism = float64(busy) / float64(wall)
+	}
+	fmt.Fprintf(w, "\nWall time %s, processor time %s, parallelism %.2f\n", duration(wall), duration(busy), parallelism)
+}
+
+func duration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+var stateColors = map[scheduler.State]ttycolors.Format{
+	scheduler.DONE:      ttycolors.FmtGreen,
+	scheduler.FAILED:    ttycolors.FmtBrightRed,
+	scheduler.DISCARDED: ttycolors.FmtBlue,
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func GetExtension(job scheduler.Job) *JobExtension {
+	return generics.Cast[*JobExtension](job.GetExtension(TYPE))
+}
+
+type JobExtension struct {
+	extensions.JobExtension
+	ext *Extension
+	id  string
+}
+
+var _ scheduler.JobExtension = (*JobExtension)(nil)
+
+func (j *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	if scheduler.IsFinished(evt.GetState()) {
+		s := &JobSummary{
+			Id:       j.id,
+			State:    evt.GetState(),
+			Timeline: evt.GetTimeline(),
+		}
+		if _, err := evt.GetJob().GetResult(); err != nil {
+			s.Error = err.Error()
+		}
+		j.ext.finished(s)
+	}
+	j.JobExtension.HandleJobEvent(evt)
+}
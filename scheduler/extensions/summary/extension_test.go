@@ -0,0 +1,92 @@
+package summary_test
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/internal/testenv"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/summary"
+)
+
+func sleeping(d time.Duration, err error) scheduler.RunnerFunc {
+	return func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+		time.Sleep(d)
+		return nil, err
+	}
+}
+
+var _ = Describe("Summary Test Environment", func() {
+	var ext *summary.Extension
+	var buf *bytes.Buffer
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		ext = summary.New(buf)
+	})
+
+	It("lists slowest jobs in descending order", func() {
+		ext.SetSlowest(2)
+		testenv.Execute(testenv.Scheduler(ext, 3),
+			scheduler.DefineJob("fast", sleeping(10*time.Millisecond, nil)),
+			scheduler.DefineJob("slow", sleeping(100*time.Millisecond, nil)),
+			scheduler.DefineJob("medium", sleeping(50*time.Millisecond, fmt.Errorf("broken"))),
+		)
+
+		Expect(ext.Jobs()).To(HaveLen(3))
+		out := buf.String()
+		Expect(out).To(MatchRegexp(`(?s)^Summary of 3 jobs
+  STATE      JOBS
+  done          2
+  failed        1
+  discarded     0
+
+Slowest jobs
+  JOB +STATE +ELAPSED +QUEUED +RUNNING +BLOCKED
+  slow\[2\] +done +[^\n]+
+  medium\[3\] +failed +[^\n]+
+
+Failed jobs
+  JOB +ERROR
+  medium\[3\] +broken
+
+Wall time `))
+		Expect(out).NotTo(ContainSubstring("fast[1]"))
+	})
+
+	It("calculates parallelism", func() {
+		testenv.Execute(testenv.Scheduler(ext, 2),
+			scheduler.DefineJob("first", sleeping(200*time.Millisecond, nil)),
+			scheduler.DefineJob("second", sleeping(200*time.Millisecond, nil)),
+		)
+
+		m := regexp.MustCompile(`parallelism ([0-9.]+)\n$`).FindStringSubmatch(buf.String())
+		Expect(m).NotTo(BeNil())
+		p := Must(strconv.ParseFloat(m[1], 64))
+		Expect(p).To(BeNumerically("~", 2, 0.25))
+	})
+
+	It("calculates sequential execution", func() {
+		testenv.Execute(testenv.Scheduler(ext, 1),
+			scheduler.DefineJob("first", sleeping(100*time.Millisecond, nil)),
+			scheduler.DefineJob("second", sleeping(100*time.Millisecond, nil)),
+		)
+
+		m := regexp.MustCompile(`parallelism ([0-9.]+)\n$`).FindStringSubmatch(buf.String())
+		Expect(m).NotTo(BeNil())
+		p := Must(strconv.ParseFloat(m[1], 64))
+		Expect(p).To(BeNumerically("~", 1, 0.1))
+	})
+
+	It("prints empty summary", func() {
+		ext.Print(buf)
+		Expect(buf.String()).To(HaveSuffix("\nWall time 0s, processor time 0s, parallelism 0.00\n"))
+	})
+})
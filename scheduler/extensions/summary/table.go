@@ -0,0 +1,69 @@
+package summary
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// cell is a table cell. The optional format is applied
+// after the cell has been aligned, so that escape sequences
+// do not influence the column width.
+type cell struct {
+	text   string
+	right  bool
+	format func(string) string
+}
+
+type table struct {
+	header []string
+	rows   [][]cell
+}
+
+func (t *table) add(cells ...cell) {
+	t.rows = append(t.rows, cells)
+}
+
+func (t *table) print(w io.Writer, indent string) {
+	widths := make([]int, len(t.header))
+	for i, h := range t.header {
+		widths[i] = utf8.RuneCountInString(h)
+	}
+	for _, r := range t.rows {
+		for i, c := range r {
+			if l := utf8.RuneCountInString(c.text); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+
+	line := make([]string, len(t.header))
+	for i, h := range t.header {
+		line[i] = pad(h, widths[i]+len(h)-utf8.RuneCountInString(h), false)
+	}
+	fmt.Fprintln(w, strings.TrimRight(indent+strings.Join(line, "  "), " "))
+	for _, r := range t.rows {
+		for i, c := range r {
+			s := c.text
+			if c.format != nil {
+				s = c.format(s)
+			}
+			line[i] = pad(s, widths[i]+len(s)-utf8.RuneCountInString(c.text), c.right)
+		}
+		fmt.Fprintln(w, strings.TrimRight(indent+strings.Join(line, "  "), " "))
+	}
+}
+
+// pad fills s with blanks up to width w. The width is given
+// in bytes of s to include escape sequences.
+func pad(s string, w int, right bool) string {
+	n := w - len(s)
+	if n <= 0 {
+		return s
+	}
+	if right {
+		return strings.Repeat(" ", n) + s
+	}
+	return s + strings.Repeat(" ", n)
+}
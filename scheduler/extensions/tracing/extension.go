@@ -0,0 +1,217 @@
+package tracing
+
+import (
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/generics"
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions"
+)
+
+const TYPE = "tracing"
+
+// DEFAULT_SERVICE is the service name used by NewForFile.
+const DEFAULT_SERVICE = "jobscheduler"
+
+// Attribute keys used for the job spans.
+const (
+	ATTR_JOB_ID     = "job.id"
+	ATTR_JOB_NAME   = "job.name"
+	ATTR_JOB_PRIO   = "job.priority"
+	ATTR_JOB_PARENT = "job.parent"
+	ATTR_JOB_STATE  = "job.state"
+	ATTR_WAIT_TIME  = "job.wait_ms"
+	ATTR_QUEUE_TIME = "job.queue_ms"
+	ATTR_RUN_TIME   = "job.run_ms"
+	ATTR_BLOCKED    = "job.blocked_ms"
+	ATTR_DURATION   = "duration_ms"
+)
+
+// Names of the span events.
+const (
+	EVENT_STARTED  = "started"
+	EVENT_BLOCKED  = "blocked"
+	EVENT_STALLED  = "stalled"
+	EVENT_FINISHED = "finished"
+)
+
+type Extension struct {
+	extensions.Extension
+	lock     sync.Mutex
+	exporter Exporter
+	errors   errors.ErrorList
+}
+
+var _ scheduler.Extension = (*Extension)(nil)
+
+// New creates an extension creating a trace span for
+// every job. The span of a sub job uses the span of its parent
+// job as parent span. Finished spans are passed to the given exporter.
+func New(exporter Exporter, nested ...scheduler.Extension) *Extension {
+	e := &Extension{exporter: exporter}
+	e.Extension = extensions.NewExtension(e, TYPE, nested...)
+	return e
+}
+
+// NewForFile creates an extension writing all spans
+// in OTLP JSON format to the given file when the extension is closed.
+func NewForFile(path string, nested ...scheduler.Extension) (*Extension, error) {
+	w, err := NewOTLPFile(path, DEFAULT_SERVICE)
+	if err != nil {
+		return nil, err
+	}
+	return New(w, nested...), nil
+}
+
+func (e *Extension) JobExtension(id string, jd scheduler.JobDefinition, parent scheduler.Job) (scheduler.JobExtension, error) {
+	var err error
+
+	j := &JobExtension{
+		ext: e,
+		span: &Span{
+			SpanId: newSpanId(),
+			Name:   jd.GetName(),
+			Attributes: []Attribute{
+				{ATTR_JOB_ID, id},
+				{ATTR_JOB_NAME, jd.GetName()},
+				{ATTR_JOB_PRIO, int(jd.GetPriority())},
+			},
+		},
+	}
+	if p := GetExtension(parent); p != nil {
+		j.span.TraceId = p.span.TraceId
+		j.span.ParentId = p.span.SpanId
+		j.span.Attributes = append(j.span.Attributes, Attribute{ATTR_JOB_PARENT, parent.GetId()})
+	} else {
+		j.span.TraceId = newTraceId()
+	}
+	j.JobExtension, err = extensions.NewJobExtension(j, TYPE, id, jd, e.Extension)
+	if err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (e *Extension) Close() error {
+	var err errors.ErrorList
+
+	e.lock.Lock()
+	err.Add(e.errors.Entries()...)
+	e.errors.Clear()
+	e.lock.Unlock()
+
+	if c, ok := e.exporter.(io.Closer); ok {
+		err.Add(c.Close())
+	}
+	err.Add(e.Extension.Close())
+	return err.Result()
+}
+
+func (e *Extension) export(span *Span) {
+	err := e.exporter.Export(span)
+	if err != nil {
+		e.lock.Lock()
+		defer e.lock.Unlock()
+		e.errors.Add(errors.Wrapf(err, "export span for job %s", span.Name))
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func GetExtension(job scheduler.Job) *JobExtension {
+	if job == nil {
+		return nil
+	}
+	return generics.Cast[*JobExtension](job.GetExtension(TYPE))
+}
+
+type JobExtension struct {
+	extensions.JobExtension
+	ext     *Extension
+	lock    sync.Mutex
+	span    *Span
+	stalled []Event
+}
+
+var _ scheduler.JobExtension = (*JobExtension)(nil)
+
+// GetTraceId returns the trace id used for the span of the job.
+func (j *JobExtension) GetTraceId() TraceId {
+	return j.span.TraceId
+}
+
+// GetSpanId returns the id of the span of the job.
+func (j *JobExtension) GetSpanId() SpanId {
+	return j.span.SpanId
+}
+
+func (j *JobExtension) HandleJobEvent(evt scheduler.JobEvent) {
+	switch {
+	case evt.GetState() == scheduler.STALLED:
+		j.lock.Lock()
+		j.stalled = append(j.stalled, Event{Name: EVENT_STALLED, Time: evt.GetTime()})
+		j.lock.Unlock()
+	case scheduler.IsFinished(evt.GetState()):
+		j.ext.export(j.finish(evt))
+	}
+	j.JobExtension.HandleJobEvent(evt)
+}
+
+func (j *JobExtension) finish(evt scheduler.JobEvent) *Span {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	t := evt.GetTimeline()
+	span := j.span
+
+	span.Start = t.Scheduled()
+	if span.Start.IsZero() {
+		span.Start = t.Applied()
+	}
+	span.End = evt.GetTime()
+
+	if s := t.Started(); !s.IsZero() {
+		span.Events = append(span.Events, Event{Name: EVENT_STARTED, Time: s})
+	}
+	for i, e := range t {
+		if e.State != scheduler.BLOCKED {
+			continue
+		}
+		end := span.End
+		if i+1 < len(t) {
+			end = t[i+1].Time
+		}
+		span.Events = append(span.Events, Event{
+			Name:       EVENT_BLOCKED,
+			Time:       e.Time,
+			Attributes: []Attribute{{ATTR_DURATION, end.Sub(e.Time)}},
+		})
+	}
+	span.Events = append(span.Events, j.stalled...)
+	span.Events = append(span.Events, Event{Name: EVENT_FINISHED, Time: span.End})
+	slices.SortStableFunc(span.Events, func(a, b Event) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	span.Attributes = append(span.Attributes,
+		Attribute{ATTR_JOB_STATE, string(evt.GetState())},
+		Attribute{ATTR_WAIT_TIME, t.WaitTime()},
+		Attribute{ATTR_QUEUE_TIME, t.QueueTime()},
+		Attribute{ATTR_RUN_TIME, t.RunTime()},
+		Attribute{ATTR_BLOCKED, t.BlockedTime()},
+	)
+
+	switch evt.GetState() {
+	case scheduler.DONE:
+		span.Status = STATUS_OK
+	case scheduler.FAILED:
+		span.Status = STATUS_ERROR
+		if _, err := evt.GetJob().GetResult(); err != nil {
+			span.Message = err.Error()
+		}
+	}
+	return span
+}
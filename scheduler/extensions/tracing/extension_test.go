@@ -0,0 +1,130 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/processors"
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/internal/testenv"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/tracing"
+)
+
+// OTLP JSON structure used to check the written spans.
+
+type request struct {
+	ResourceSpans []struct {
+		Resource struct {
+			Attributes []attribute `json:"attributes"`
+		} `json:"resource"`
+		ScopeSpans []struct {
+			Spans []span `json:"spans"`
+		} `json:"scopeSpans"`
+	} `json:"resourceSpans"`
+}
+
+type span struct {
+	TraceId      string      `json:"traceId"`
+	SpanId       string      `json:"spanId"`
+	ParentSpanId string      `json:"parentSpanId"`
+	Name         string      `json:"name"`
+	Attributes   []attribute `json:"attributes"`
+	Events       []struct {
+		Name       string      `json:"name"`
+		Attributes []attribute `json:"attributes"`
+	} `json:"events"`
+	Status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+}
+
+type attribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+		IntValue    string `json:"intValue"`
+	} `json:"value"`
+}
+
+func value(attrs []attribute, key string) string {
+	for _, a := range attrs {
+		if a.Key == key {
+			return a.Value.StringValue + a.Value.IntValue
+		}
+	}
+	return ""
+}
+
+var _ = Describe("Tracing Test Environment", func() {
+	It("writes job hierarchy in OTLP format", func() {
+		buf := &bytes.Buffer{}
+		sched := testenv.Scheduler(tracing.New(tracing.NewOTLPWriter(buf, "test")), 2)
+
+		var ids [2]tracing.SpanId
+
+		parent := Must(sched.ScheduleDefinition(scheduler.DefineJob("parent", scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			child := Must(ctx.Scheduler().ScheduleDefinition(scheduler.DefineJob("child", scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+				processors.Sleep(ctx, 100*time.Millisecond)
+				return nil, fmt.Errorf("failed")
+			})), ctx.Job()))
+			ids[1] = tracing.GetExtension(child).GetSpanId()
+			Expect(tracing.GetExtension(child).GetTraceId()).To(Equal(tracing.GetExtension(ctx.Job()).GetTraceId()))
+			child.Wait()
+			return nil, nil
+		}))))
+		ids[0] = tracing.GetExtension(parent).GetSpanId()
+		MustBeSuccessful(sched.Shutdown(context.Background()))
+
+		var req request
+		MustBeSuccessful(json.Unmarshal(buf.Bytes(), &req))
+		Expect(req.ResourceSpans).To(HaveLen(1))
+		Expect(value(req.ResourceSpans[0].Resource.Attributes, "service.name")).To(Equal("test"))
+		spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+		Expect(spans).To(HaveLen(2))
+
+		// the child finishes first.
+		c, p := spans[0], spans[1]
+		Expect(p.Name).To(Equal("parent"))
+		Expect(p.SpanId).To(Equal(ids[0].String()))
+		Expect(p.ParentSpanId).To(BeEmpty())
+		Expect(p.TraceId).To(HaveLen(32))
+		Expect(p.Status.Code).To(Equal(int(tracing.STATUS_OK)))
+		Expect(value(p.Attributes, tracing.ATTR_JOB_ID)).To(Equal("parent[1]"))
+		Expect(value(p.Attributes, tracing.ATTR_JOB_STATE)).To(Equal("done"))
+
+		Expect(c.Name).To(Equal("child"))
+		Expect(c.SpanId).To(Equal(ids[1].String()))
+		Expect(c.ParentSpanId).To(Equal(p.SpanId))
+		Expect(c.TraceId).To(Equal(p.TraceId))
+		Expect(c.Status.Code).To(Equal(int(tracing.STATUS_ERROR)))
+		Expect(c.Status.Message).To(Equal("failed"))
+		Expect(value(c.Attributes, tracing.ATTR_JOB_PARENT)).To(Equal("parent[1]"))
+		Expect(value(c.Attributes, tracing.ATTR_JOB_STATE)).To(Equal("failed"))
+		Expect(strconv.Atoi(value(c.Attributes, tracing.ATTR_BLOCKED))).To(BeNumerically(">=", 90))
+
+		var names []string
+		for _, e := range c.Events {
+			names = append(names, e.Name)
+		}
+		Expect(names).To(Equal([]string{tracing.EVENT_STARTED, tracing.EVENT_BLOCKED, tracing.EVENT_FINISHED}))
+		Expect(strconv.Atoi(value(c.Events[1].Attributes, tracing.ATTR_DURATION))).To(BeNumerically(">=", 90))
+	})
+
+	It("reports export errors", func() {
+		sched := testenv.Scheduler(tracing.New(tracing.ExporterFunc(func(span *tracing.Span) error {
+			return fmt.Errorf("export failed")
+		})), 1)
+
+		Must(sched.ScheduleDefinition(scheduler.DefineJob("job", testenv.Output("", nil))))
+		Expect(sched.Shutdown(context.Background())).To(MatchError(ContainSubstring("export span for job job: export failed")))
+	})
+})
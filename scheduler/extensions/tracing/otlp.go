@@ -0,0 +1,204 @@
+package tracing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/mandelsoft/goutils/errors"
+)
+
+// OTLPWriter is an Exporter collecting all spans
+// and writing them in the OTLP JSON format
+// (an ExportTraceServiceRequest) when it is closed.
+// The result can be loaded into trace viewers
+// supporting OTLP JSON files.
+type OTLPWriter struct {
+	lock    sync.Mutex
+	service string
+	writer  io.Writer
+	closer  io.Closer
+	spans   []*Span
+}
+
+var _ Exporter = (*OTLPWriter)(nil)
+var _ io.Closer = (*OTLPWriter)(nil)
+
+// NewOTLPWriter creates an exporter writing to the given writer.
+func NewOTLPWriter(w io.Writer, service string) *OTLPWriter {
+	return &OTLPWriter{writer: w, service: service}
+}
+
+// NewOTLPFile creates an exporter writing to the given file.
+func NewOTLPFile(path string, service string) (*OTLPWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &OTLPWriter{writer: f, closer: f, service: service}, nil
+}
+
+func (w *OTLPWriter) Export(span *Span) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.spans = append(w.spans, span)
+	return nil
+}
+
+func (w *OTLPWriter) Close() error {
+	var list errors.ErrorList
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	spans := make([]otlpSpan, 0, len(w.spans))
+	for _, s := range w.spans {
+		spans = append(spans, toOTLPSpan(s))
+	}
+	w.spans = nil
+
+	req := otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{toOTLPAttribute(Attribute{"service.name", w.service})},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/mandelsoft/jobscheduler"},
+				Spans: spans,
+			}},
+		}},
+	}
+	enc := json.NewEncoder(w.writer)
+	enc.SetIndent("", "  ")
+	list.Add(enc.Encode(&req))
+	if w.closer != nil {
+		list.Add(w.closer.Close())
+	}
+	return list.Result()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceId           string          `json:"traceId"`
+	SpanId            string          `json:"spanId"`
+	ParentSpanId      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Events            []otlpEvent     `json:"events,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string          `json:"timeUnixNano"`
+	Name         string          `json:"name"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+// kindInternal is the OTLP span kind for internal operations.
+const kindInternal = 1
+
+func toOTLPSpan(s *Span) otlpSpan {
+	r := otlpSpan{
+		TraceId:           s.TraceId.String(),
+		SpanId:            s.SpanId.String(),
+		Name:              s.Name,
+		Kind:              kindInternal,
+		StartTimeUnixNano: unixNano(s.Start),
+		EndTimeUnixNano:   unixNano(s.End),
+		Status:            otlpStatus{Code: int(s.Status), Message: s.Message},
+	}
+	if !s.ParentId.IsZero() {
+		r.ParentSpanId = s.ParentId.String()
+	}
+	for _, a := range s.Attributes {
+		r.Attributes = append(r.Attributes, toOTLPAttribute(a))
+	}
+	for _, e := range s.Events {
+		oe := otlpEvent{TimeUnixNano: unixNano(e.Time), Name: e.Name}
+		for _, a := range e.Attributes {
+			oe.Attributes = append(oe.Attributes, toOTLPAttribute(a))
+		}
+		r.Events = append(r.Events, oe)
+	}
+	return r
+}
+
+func toOTLPAttribute(a Attribute) otlpAttribute {
+	var v otlpValue
+
+	switch t := a.Value.(type) {
+	case string:
+		v.StringValue = &t
+	case bool:
+		v.BoolValue = &t
+	case int:
+		s := strconv.FormatInt(int64(t), 10)
+		v.IntValue = &s
+	case int64:
+		s := strconv.FormatInt(t, 10)
+		v.IntValue = &s
+	case float64:
+		v.DoubleValue = &t
+	case time.Duration:
+		s := strconv.FormatInt(t.Milliseconds(), 10)
+		v.IntValue = &s
+	default:
+		s := fmt.Sprint(t)
+		v.StringValue = &s
+	}
+	return otlpAttribute{Key: a.Key, Value: v}
+}
+
+func unixNano(t time.Time) string {
+	if t.IsZero() {
+		return "0"
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
@@ -0,0 +1,93 @@
+package tracing
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+// TraceId identifies a trace. All jobs of a job hierarchy
+// share the trace id of their root job.
+type TraceId [16]byte
+
+func (id TraceId) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanId identifies a single span.
+type SpanId [8]byte
+
+func (id SpanId) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id SpanId) IsZero() bool {
+	return id == SpanId{}
+}
+
+func newTraceId() TraceId {
+	var id TraceId
+	rand.Read(id[:])
+	return id
+}
+
+func newSpanId() SpanId {
+	var id SpanId
+	rand.Read(id[:])
+	return id
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// StatusCode describes the outcome of a span.
+type StatusCode int
+
+const (
+	STATUS_UNSET StatusCode = 0
+	STATUS_OK    StatusCode = 1
+	STATUS_ERROR StatusCode = 2
+)
+
+// Attribute is a key value pair describing a span or span event.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Event describes a point in time or, for blocked intervals,
+// a period during the execution of a job.
+type Event struct {
+	Name       string
+	Time       time.Time
+	Attributes []Attribute
+}
+
+// Span describes the execution of a single job.
+type Span struct {
+	TraceId    TraceId
+	SpanId     SpanId
+	ParentId   SpanId
+	Name       string
+	Start      time.Time
+	End        time.Time
+	Attributes []Attribute
+	Events     []Event
+
+	Status  StatusCode
+	Message string
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+// Exporter gets all finished spans.
+// If it implements io.Closer, it is closed together
+// with the extension.
+type Exporter interface {
+	Export(span *Span) error
+}
+
+type ExporterFunc func(span *Span) error
+
+func (f ExporterFunc) Export(span *Span) error {
+	return f(span)
+}
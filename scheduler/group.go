@@ -0,0 +1,172 @@
+package scheduler
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/mandelsoft/goutils/errors"
+	"github.com/mandelsoft/goutils/optionutils"
+	"github.com/mandelsoft/jobscheduler/processors"
+)
+
+// Group spawns child jobs for the job of a SchedulingContext
+// and waits for their completion.
+// While waiting, the processor of the calling job is released.
+type Group struct {
+	ctx    SchedulingContext
+	wait   *processors.WaitGroup
+	limit  processors.Semaphore
+	cancel bool
+
+	lock  sync.Mutex
+	jobs  []Job
+	errs  []error
+	first error
+}
+
+var _ EventHandler = (*Group)(nil)
+
+// NewGroup creates a new Group spawning child jobs
+// of the job of the given SchedulingContext.
+func NewGroup(ctx SchedulingContext) *Group {
+	return &Group{ctx: ctx, wait: processors.NewWaitGroup()}
+}
+
+// SetLimit limits the number of unfinished jobs of the group.
+// If the limit is reached, Go blocks until a job is finished.
+// A limit of 0 disables the limitation (default).
+// It must be called before the first job is spawned.
+func (g *Group) SetLimit(n int) *Group {
+	if n > 0 {
+		g.limit = processors.NewSemaphore(n)
+	} else {
+		g.limit = nil
+	}
+	return g
+}
+
+// SetCancelOnError enables or disables the cancellation of all other
+// jobs of the group, if a job fails. Called without argument it
+// enables the cancellation; it is disabled by default. If enabled,
+// Wait returns only the first error and no new jobs can be spawned.
+func (g *Group) SetCancelOnError(b ...bool) *Group {
+	g.cancel = optionutils.BoolOption(b...)
+	return g
+}
+
+// Go spawns a new child job with the given name and runner.
+func (g *Group) Go(name string, runner Runner) (Job, error) {
+	return g.GoDefinition(DefineJob(name, runner))
+}
+
+// GoDefinition spawns a new child job for the given job definition.
+func (g *Group) GoDefinition(def JobDefinition) (Job, error) {
+	if err := g.cancelled(); err != nil {
+		return nil, err
+	}
+
+	if g.limit != nil {
+		err := g.limit.Acquire(g.ctx, 1)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	job, err := g.ctx.Scheduler().Apply(newDefinition(def).AddHandler(g), g.ctx.Job())
+	if err != nil {
+		if g.limit != nil {
+			g.limit.Release(1)
+		}
+		return nil, err
+	}
+	g.wait.Add(1)
+
+	err = job.Schedule()
+	if err != nil {
+		// discard job to finish it.
+		job.Cancel()
+		return nil, err
+	}
+
+	// the job is registered after it has been scheduled, only.
+	// If a sibling failed in the meantime, it is cancelled here.
+	g.lock.Lock()
+	g.jobs = append(g.jobs, job)
+	cancel := g.cancel && g.first != nil
+	g.lock.Unlock()
+
+	if cancel {
+		job.Cancel()
+	}
+	return job, nil
+}
+
+func (g *Group) cancelled() error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.cancel {
+		return g.first
+	}
+	return nil
+}
+
+// Jobs returns the jobs spawned by the group.
+func (g *Group) Jobs() []Job {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return slices.Clone(g.jobs)
+}
+
+// Wait waits until all spawned jobs are finished.
+// It returns the first error, if the group cancels jobs on errors,
+// or the joined errors of all failed jobs.
+func (g *Group) Wait() error {
+	err := g.wait.Wait(g.ctx)
+	if err != nil {
+		return err
+	}
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.cancel {
+		return g.first
+	}
+	return errors.Join(g.errs...)
+}
+
+func (g *Group) HandleJobEvent(evt JobEvent) {
+	if !IsFinished(evt.GetState()) {
+		return
+	}
+
+	var cancel []Job
+
+	job := evt.GetJob()
+	_, err := job.GetResult()
+	if err != nil {
+		err = errors.Wrapf(err, "job %s", job.GetId())
+
+		g.lock.Lock()
+		g.errs = append(g.errs, err)
+		if g.first == nil {
+			g.first = err
+			if g.cancel {
+				cancel = slices.Clone(g.jobs)
+			}
+		}
+		g.lock.Unlock()
+	}
+
+	// cancel siblings outside the group lock, because
+	// discarding a job synchronously reports its final state.
+	for _, j := range cancel {
+		if j != job {
+			j.Cancel()
+		}
+	}
+	if g.limit != nil {
+		g.limit.Release(1)
+	}
+	g.wait.Done()
+}
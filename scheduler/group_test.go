@@ -0,0 +1,173 @@
+package scheduler_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+)
+
+var _ = Describe("Group Test Environment", func() {
+	var sched scheduler.Scheduler
+
+	BeforeEach(func() {
+		sched = scheduler.New()
+		sched.AddProcessor()
+		sched.Run(nil)
+	})
+
+	AfterEach(func() {
+		sched.Cancel()
+		sched.Wait()
+	})
+
+	run := func(runner scheduler.RunnerFunc) error {
+		job := Must(sched.Apply(scheduler.DefineJob("parent", runner)))
+		MustBeSuccessful(job.Schedule())
+		job.Wait()
+		_, err := job.GetResult()
+		return err
+	}
+
+	It("waits for jobs", func() {
+		var count atomic.Int32
+
+		MustBeSuccessful(run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			g := scheduler.NewGroup(ctx)
+			for i := 0; i < 3; i++ {
+				Must(g.Go(fmt.Sprintf("child%d", i), scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					time.Sleep(50 * time.Millisecond)
+					count.Add(1)
+					return nil, nil
+				})))
+			}
+			Expect(g.Jobs()).To(HaveLen(3))
+			return nil, g.Wait()
+		}))
+		Expect(count.Load()).To(Equal(int32(3)))
+	})
+
+	It("joins errors", func() {
+		err := run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			g := scheduler.NewGroup(ctx)
+			for i := 0; i < 3; i++ {
+				Must(g.Go(fmt.Sprintf("child%d", i), scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					if i == 1 {
+						return nil, nil
+					}
+					return nil, fmt.Errorf("failed %d", i)
+				})))
+			}
+			return nil, g.Wait()
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(MatchRegexp(`job child0\[[0-9]+\]: failed 0`))
+		Expect(err.Error()).To(MatchRegexp(`job child2\[[0-9]+\]: failed 2`))
+	})
+
+	It("cancels siblings", func() {
+		sched.AddProcessor(2)
+
+		var cancelled atomic.Bool
+
+		err := run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			g := scheduler.NewGroup(ctx).SetCancelOnError()
+			Must(g.Go("waiting", scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+				select {
+				case <-ctx.Done():
+					cancelled.Store(true)
+					return nil, ctx.Err()
+				case <-time.After(2 * time.Second):
+					return nil, nil
+				}
+			})))
+			Must(g.Go("failing", scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+				return nil, fmt.Errorf("failed")
+			})))
+			err := g.Wait()
+			ExpectError(g.Go("late", nil)).To(BeIdenticalTo(err))
+			return nil, err
+		})
+		Expect(err).To(MatchError(MatchRegexp(`^job failing\[[0-9]+\]: failed$`)))
+		Expect(cancelled.Load()).To(BeTrue())
+	})
+
+	It("cancels jobs spawned after a failure", func() {
+		sched.AddProcessor(2)
+
+		var spawned []scheduler.Job
+		err := run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			g := scheduler.NewGroup(ctx).SetCancelOnError()
+			for i := 0; i < 50; i++ {
+				j, err := g.Go(fmt.Sprintf("child%d", i), scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					if i == 0 {
+						return nil, fmt.Errorf("failed")
+					}
+					select {
+					case <-ctx.Done():
+						return nil, ctx.Err()
+					case <-time.After(2 * time.Second):
+						return nil, nil
+					}
+				}))
+				if err != nil {
+					break
+				}
+				spawned = append(spawned, j)
+			}
+			return nil, g.Wait()
+		})
+		Expect(err).To(MatchError(MatchRegexp(`^job child0\[[0-9]+\]: failed$`)))
+		for _, j := range spawned {
+			Expect(scheduler.IsFinished(j.GetState())).To(BeTrue())
+		}
+	})
+
+	It("discards unscheduled jobs on cancel", func() {
+		job := Must(sched.Apply(scheduler.DefineJob("initial", scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			return nil, nil
+		}))))
+		job.Cancel()
+		job.Wait()
+		Expect(job.GetState()).To(Equal(scheduler.DISCARDED))
+	})
+
+	It("limits jobs", func() {
+		sched.AddProcessor(2)
+
+		var lock sync.Mutex
+		active := 0
+		max := 0
+		MustBeSuccessful(run(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+			g := scheduler.NewGroup(ctx).SetLimit(2)
+			for i := 0; i < 5; i++ {
+				Must(g.Go(fmt.Sprintf("child%d", i), scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					lock.Lock()
+					active++
+					max = maxOf(max, active)
+					lock.Unlock()
+					time.Sleep(50 * time.Millisecond)
+					lock.Lock()
+					active--
+					lock.Unlock()
+					return nil, nil
+				})))
+			}
+			return nil, g.Wait()
+		}))
+		Expect(max).To(Equal(2))
+	})
+})
+
+func maxOf(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"io"
 	"slices"
+	"time"
 
 	"github.com/mandelsoft/goutils/general"
+	"github.com/mandelsoft/goutils/optionutils"
 	"github.com/mandelsoft/goutils/sliceutils"
 	"github.com/mandelsoft/jobscheduler/processors"
 	"github.com/mandelsoft/jobscheduler/queue"
@@ -35,6 +37,11 @@ const (
 	FAILED State = "failed"
 	// job not started because of failed start condition
 	DISCARDED State = "discarded"
+
+	// STALLED is no real job state. It is reported by a JobEvent
+	// if a running job missed its heartbeat timeout.
+	// The job keeps its actual state.
+	STALLED State = "stalled"
 )
 
 func IsFinished(state State) bool {
@@ -84,14 +91,50 @@ type Scheduler interface {
 
 	AddProcessor(n ...int)
 	RemoveProcessor(ctx context.Context)
+	// GetProcessorCount returns the number of processors
+	// added, but not yet removed.
+	GetProcessorCount() int
+
+	// SetExtension sets the extension used for new jobs
+	// and calls its Setup method. A setup error is
+	// reported by Run.
 	SetExtension(e Extension)
 
+	// SetPanicRecovery enables (default) or disables the recovery
+	// of panics in job runners. If enabled, a panicking job
+	// is set to state FAILED with a *PanicError and the scheduler
+	// continues. Otherwise, the panic crashes the process.
+	SetPanicRecovery(enabled bool)
+
+	// SetDeadlockDetection enables a watchdog detecting states
+	// where no job can progress anymore: there are no running,
+	// ready or pending jobs, but blocked jobs for at least
+	// the given period. Waiting jobs alone are not stuck, their
+	// conditions may be triggered from outside. Jobs blocked in
+	// operations ending on their own (see processors.SelfTerminating),
+	// like a sleep, are not stuck either.
+	// Such a state is reported once by a DeadlockEvent
+	// and the optional handler. A period of 0 disables the detection.
+	SetDeadlockDetection(period time.Duration, handler ...DeadlockHandler)
+
+	RegisterHandler(handler SchedulerEventHandler)
+	UnregisterHandler(handler SchedulerEventHandler)
+
 	Run(ctx context.Context) error
 
 	JobManager
 
 	Cancel()
 	Wait()
+
+	// Shutdown waits until all scheduled jobs are finished
+	// or the context is cancelled, stops the processors
+	// and closes the extension. Jobs still running when the
+	// context is cancelled are cancelled, too.
+	// The extension is closed by the first call, only.
+	// It returns the errors of closing the extension
+	// and the job extensions.
+	Shutdown(ctx context.Context) error
 }
 
 type JobManager interface {
@@ -107,6 +150,8 @@ type Job interface {
 	GetScheduler() Scheduler
 
 	GetState() State
+	GetTimeline() Timeline
+	GetProgress() ProgressInfo
 	GetResult() (Result, error)
 	GetPriority() Priority
 
@@ -126,6 +171,17 @@ type SchedulingContext interface {
 
 	Job() Job
 	Scheduler() Scheduler
+
+	// Heartbeat indicates the progress of a running job.
+	// The optional info is forwarded to the job extensions.
+	// If a job definition uses a heartbeat timeout, a running job
+	// must call Heartbeat within this timeout. Otherwise,
+	// it is reported as STALLED.
+	Heartbeat(info ...any)
+
+	// Progress provides access to the progress reporting
+	// of the job.
+	Progress() Progress
 }
 
 type Result interface{}
@@ -152,6 +208,8 @@ type JobDefinition interface {
 	GetPriority() Priority
 	GetHandlers() []EventHandler
 	GetExtension(typ ...string) ExtensionDefinition
+	GetHeartbeatTimeout() time.Duration
+	IsCancelOnStall() bool
 }
 
 type DefaultJobDefinition struct {
@@ -162,6 +220,8 @@ type DefaultJobDefinition struct {
 	priority  Priority
 	handlers  []EventHandler
 	extension ExtensionDefinition
+	heartbeat time.Duration
+	cancel    bool
 }
 
 var _ JobDefinition = DefaultJobDefinition{}
@@ -239,6 +299,29 @@ func (d DefaultJobDefinition) GetExtension(typ ...string) ExtensionDefinition {
 	return d.extension.GetExtension(typ[0])
 }
 
+func (d DefaultJobDefinition) GetHeartbeatTimeout() time.Duration {
+	return d.heartbeat
+}
+
+// SetHeartbeatTimeout sets the time a running job may work
+// without calling SchedulingContext.Heartbeat before it
+// is reported as STALLED. 0 disables the stall detection.
+func (d DefaultJobDefinition) SetHeartbeatTimeout(t time.Duration) DefaultJobDefinition {
+	d.heartbeat = t
+	return d
+}
+
+func (d DefaultJobDefinition) IsCancelOnStall() bool {
+	return d.cancel
+}
+
+// SetCancelOnStall requests a job to be cancelled if it
+// is detected as STALLED.
+func (d DefaultJobDefinition) SetCancelOnStall(b ...bool) DefaultJobDefinition {
+	d.cancel = optionutils.BoolOption(b...)
+	return d
+}
+
 func newDefinition(def JobDefinition) DefaultJobDefinition {
 	return DefaultJobDefinition{
 		name:      def.GetName(),
@@ -248,5 +331,7 @@ func newDefinition(def JobDefinition) DefaultJobDefinition {
 		priority:  def.GetPriority(),
 		handlers:  def.GetHandlers(),
 		extension: def.GetExtension(),
+		heartbeat: def.GetHeartbeatTimeout(),
+		cancel:    def.IsCancelOnStall(),
 	}
 }
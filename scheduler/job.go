@@ -6,7 +6,9 @@ import (
 	"io"
 	"slices"
 	"sync"
+	"time"
 
+	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/jobscheduler/ctxutils"
 	"github.com/mandelsoft/jobscheduler/scheduler/condition"
 	"github.com/mandelsoft/jobscheduler/syncutils/synclog"
@@ -31,7 +33,15 @@ type job struct {
 
 	definition DefaultJobDefinition
 	state      stateJobs
+	timeline   Timeline
+	blockedOn  any
+	lastBeat   time.Time
+	progress   ProgressInfo
+	stalled    bool
 	handlers   []EventHandler
+	// scheduled indicates that the job is counted
+	// by the scheduler until it is finished.
+	scheduled bool
 
 	extension JobExtension
 	writer    io.Writer
@@ -81,6 +91,13 @@ func (j *job) GetState() State {
 	return j.state.State()
 }
 
+func (j *job) GetTimeline() Timeline {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return slices.Clone(j.timeline)
+}
+
 func (j *job) GetExtension(typ string) JobExtension {
 	if j.extension == nil {
 		return nil
@@ -92,7 +109,10 @@ func (j *job) Cancel() {
 	j.lock.Lock()
 
 	if j.state.State() == INITIAL {
+		// setState releases the job lock.
 		j.setState(j.scheduler.discarded)
+		j.cancel()
+		return
 	}
 	j.cancel()
 	j.lock.Unlock()
@@ -120,33 +140,56 @@ func (j *job) setState(jobs stateJobs) {
 
 	old := j.state
 	j.state = jobs
+	if jobs.State() != BLOCKED {
+		j.blockedOn = nil
+	}
+	if jobs.State() == RUNNING {
+		j.lastBeat = time.Now()
+		j.stalled = false
+	}
+	now := time.Now()
+	j.timeline = append(j.timeline, TimelineEntry{jobs.State(), now})
 	jobs.Add(j)
 	if old != nil && (old.State() == INITIAL || old.State() == WAITING || old.State() == PENDING) && jobs.State() == RUNNING {
 		j.extension.Start()
 	}
 	j.extension.SetState(jobs.State())
-	e := JobEvent{j, jobs.State()}
+	e := JobEvent{job: j, state: jobs.State(), time: now, timeline: slices.Clone(j.timeline)}
 
 	wg := &sync.WaitGroup{}
 	wg.Add(1)
 	// fmt.Printf("report event %s\n", e)
-	go func(handlers []EventHandler) {
+	go func(ext JobExtension, handlers []EventHandler) {
 		// fmt.Printf("report start %s\n", e)
+		ext.HandleJobEvent(e)
 		for _, h := range handlers {
 			h.HandleJobEvent(e)
 		}
 		j.scheduler.Raise(e)
 		// fmt.Printf("report finished %s\n", e)
 		wg.Done()
-	}(slices.Clone(j.handlers))
+	}(j.extension, slices.Clone(j.handlers))
 
 	j.lock.Unlock()
 	wg.Wait()
 	switch jobs.State() {
-	case DONE, DISCARDED:
+	case DONE, DISCARDED, FAILED:
 		// fmt.Printf("job %s %s\n", j.id, jobs.State())
-		j.extension.Close()
-		j.wg.Done()
+		if err := j.extension.Close(); err != nil {
+			log.Error("closing extension for job {{job}} failed", "job", j.id, "scheduler", j.scheduler.name, "error", err)
+			j.scheduler.addError(errors.Wrapf(err, "job %s", j.id))
+		}
+		if jobs.State() == DISCARDED && j.parent != nil {
+			// discarded jobs are never finished by a processor.
+			j.parent.finishChild(j)
+		}
+		if j.scheduled {
+			j.wg.Done()
+			if j.definition.heartbeat > 0 {
+				j.scheduler.removeHeartbeatJob()
+			}
+			j.scheduler.jobs.Done()
+		}
 	}
 }
 
@@ -184,8 +227,14 @@ func (j *job) Schedule() error {
 	}
 
 	log.Debug("schedule job", "job", j.id)
-
+	j.scheduled = true
 	j.wg.Add(1)
+	j.scheduler.jobs.Add(1)
+	if j.definition.heartbeat > 0 {
+		// only scheduled jobs are watched for stalls.
+		j.scheduler.addHeartbeatJob()
+	}
+
 	if j.definition.discard != nil {
 		js := j.definition.discard.GetState()
 		if js.Valid {
@@ -213,7 +262,11 @@ func (j *job) finishChild(c *job) {
 		}
 	}
 	if j.state.State() == ZOMBIE && len(j.children) == 0 {
-		j.setState(j.scheduler.done)
+		if j.err != nil {
+			j.setState(j.scheduler.failed)
+		} else {
+			j.setState(j.scheduler.done)
+		}
 	} else {
 		j.lock.Unlock()
 	}
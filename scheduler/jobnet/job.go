@@ -1,8 +1,11 @@
 package jobnet
 
 import (
+	"time"
+
 	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/general"
+	"github.com/mandelsoft/goutils/optionutils"
 	"github.com/mandelsoft/goutils/set"
 	"github.com/mandelsoft/goutils/sliceutils"
 	"github.com/mandelsoft/jobscheduler/scheduler"
@@ -23,6 +26,8 @@ type Job struct {
 	priority  Priority
 	handlers  []scheduler.EventHandler
 	extension scheduler.ExtensionDefinition
+	heartbeat time.Duration
+	cancel    bool
 }
 
 type Runner interface {
@@ -88,6 +93,16 @@ func (d Job) GetExtension(typ ...string) ExtensionDefinition {
 	return d.extension.GetExtension(typ[0])
 }
 
+func (d Job) SetHeartbeatTimeout(t time.Duration) Job {
+	d.heartbeat = t
+	return d
+}
+
+func (d Job) SetCancelOnStall(b ...bool) Job {
+	d.cancel = optionutils.BoolOption(b...)
+	return d
+}
+
 func (d Job) validate(jobs map[string]Job) (set.Set[string], error) {
 	result := errors.ErrListf("job %q", d.name)
 	required := set.Set[string]{}
@@ -99,3 +114,11 @@ func (d Job) validate(jobs map[string]Job) (set.Set[string], error) {
 	}
 	return required, result.Result()
 }
+
+// Command provides a Runner executing the given
+// command for every instance of the net.
+func Command(cmd scheduler.CommandRunner) Runner {
+	return RunnerFunc(func(ctx *NetContext) scheduler.Runner {
+		return cmd
+	})
+}
@@ -0,0 +1,50 @@
+package jobnet_test
+
+import (
+	"bytes"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/extensions/buffered"
+	"github.com/mandelsoft/jobscheduler/scheduler/jobnet"
+)
+
+var _ = Describe("Job Test Environment", func() {
+	var sched scheduler.Scheduler
+	var buf *bytes.Buffer
+
+	BeforeEach(func() {
+		buf = &bytes.Buffer{}
+		sched = scheduler.New()
+		sched.SetExtension(buffered.New(buf).SetOrder(buffered.COMPLETION_ORDER))
+		sched.AddProcessor(2)
+		sched.Run(nil)
+	})
+
+	AfterEach(func() {
+		sched.Cancel()
+		sched.Wait()
+	})
+
+	It("executes commands", func() {
+		first := jobnet.DefineJob("first", jobnet.Command(scheduler.Command("echo", "first")))
+		second := jobnet.DefineJob("second", jobnet.Command(scheduler.Command("sh", "-c", "echo second; exit 2"))).
+			SetCondition(jobnet.DependsOn("first"))
+		def := Must(jobnet.DefineNet("net").AddJob(first, second).For(nil))
+
+		job := Must(sched.ScheduleDefinition(def))
+		job.Wait()
+		_, err := job.GetResult()
+		MustBeSuccessful(err)
+
+		Expect(buf.String()).To(HavePrefix(`  - JOB first[2] done
+    first
+  - JOB second[3] failed
+    second
+- JOB net[1] done
+`))
+	})
+})
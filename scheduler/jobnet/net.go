@@ -161,6 +161,8 @@ func (r *netRunner) Run(ctx scheduler.SchedulingContext) (scheduler.Result, erro
 		job, err := ctx.Scheduler().Apply(scheduler.DefineJob(n, r.runner(d.runner.CreateRunner(netctx))).
 			SetExtension(d.extension).
 			SetPriority(d.priority).
+			SetHeartbeatTimeout(d.heartbeat).
+			SetCancelOnStall(d.cancel).
 			SetCondition(c).
 			SetDiscardCondition(dc), ctx.Job())
 		if err != nil {
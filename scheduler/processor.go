@@ -3,14 +3,16 @@ package scheduler
 import (
 	"context"
 	"io"
+	"runtime/debug"
 
+	"github.com/mandelsoft/goutils/general"
 	"github.com/mandelsoft/jobscheduler/ctxutils"
 )
 
 type schedulingContext struct {
 	context.Context
 	io.Writer
-	job Job
+	job *job
 }
 
 func (c *schedulingContext) Job() Job {
@@ -21,6 +23,14 @@ func (c *schedulingContext) Scheduler() Scheduler {
 	return c.job.GetScheduler()
 }
 
+func (c *schedulingContext) Progress() Progress {
+	return &progress{c.job}
+}
+
+func (c *schedulingContext) Heartbeat(info ...any) {
+	c.job.heartbeat(general.Optional(info...))
+}
+
 ///////////////////////////////////////////////////////////////////////////////
 
 type processor struct {
@@ -46,9 +56,23 @@ func (p *processor) Run(ctx context.Context) {
 		} else {
 			log.Debug("start job {{job}} on processor {{processor}}", "job", job.id, "processor", p.id, "scheduler", p.scheduler.name)
 			job.SetState(p.scheduler.running)
-			job.result, job.err = job.definition.runner.Run(&schedulingContext{setJob(job.ctx, job), job.writer, job})
+			job.result, job.err = p.run(job)
 			job.finish()
 			log.Debug("job {{job}} finished", "job", job.id, "processor", p.id, "scheduler", p.scheduler.name)
 		}
 	}
 }
+
+func (p *processor) run(job *job) (result Result, err error) {
+	ctx := &schedulingContext{setJob(job.ctx, job), job.writer, job}
+	if !p.scheduler.recoverPanics.Load() {
+		return job.definition.runner.Run(ctx)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Error("job {{job}} panicked", "job", job.id, "processor", p.id, "scheduler", p.scheduler.name, "panic", r)
+			result, err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return job.definition.runner.Run(ctx)
+}
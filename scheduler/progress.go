@@ -0,0 +1,101 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/mandelsoft/goutils/general"
+)
+
+// ProgressInfo describes the progress of a job.
+type ProgressInfo struct {
+	Total   int
+	Current int
+	Message string
+}
+
+// Percent returns the completion in percent, or -1
+// if no total is known.
+func (p ProgressInfo) Percent() float64 {
+	if p.Total <= 0 {
+		return -1
+	}
+	return float64(p.Current) * 100 / float64(p.Total)
+}
+
+// Progress is used by a job runner to report its progress.
+// Every update is forwarded to the job extensions by
+// calling JobExtension.Progress and counts as heartbeat.
+type Progress interface {
+	SetTotal(total int)
+	Set(current int)
+	// Inc increments the current progress by the
+	// given delta (default 1).
+	Inc(delta ...int)
+	SetMessage(msg string)
+	// Complete sets the current progress to the total.
+	Complete()
+
+	Get() ProgressInfo
+}
+
+type progress struct {
+	job *job
+}
+
+var _ Progress = (*progress)(nil)
+
+func (p *progress) SetTotal(total int) {
+	p.job.updateProgress(func(info *ProgressInfo) {
+		info.Total = total
+	})
+}
+
+func (p *progress) Set(current int) {
+	p.job.updateProgress(func(info *ProgressInfo) {
+		info.Current = current
+	})
+}
+
+func (p *progress) Inc(delta ...int) {
+	d := general.OptionalDefaulted(1, delta...)
+	p.job.updateProgress(func(info *ProgressInfo) {
+		info.Current += d
+	})
+}
+
+func (p *progress) SetMessage(msg string) {
+	p.job.updateProgress(func(info *ProgressInfo) {
+		info.Message = msg
+	})
+}
+
+func (p *progress) Complete() {
+	p.job.updateProgress(func(info *ProgressInfo) {
+		info.Current = info.Total
+	})
+}
+
+func (p *progress) Get() ProgressInfo {
+	return p.job.GetProgress()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+
+func (j *job) GetProgress() ProgressInfo {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+
+	return j.progress
+}
+
+func (j *job) updateProgress(mod func(info *ProgressInfo)) {
+	j.lock.Lock()
+	mod(&j.progress)
+	info := j.progress
+	j.lastBeat = time.Now()
+	j.stalled = false
+	ext := j.extension
+	j.lock.Unlock()
+
+	ext.Progress(info)
+}
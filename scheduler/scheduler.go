@@ -3,12 +3,15 @@ package scheduler
 import (
 	"context"
 	"fmt"
+	"io"
 	"sync/atomic"
 
+	"github.com/mandelsoft/goutils/errors"
 	"github.com/mandelsoft/goutils/general"
 	"github.com/mandelsoft/jobscheduler/ctxutils"
 	"github.com/mandelsoft/jobscheduler/processors"
 	"github.com/mandelsoft/jobscheduler/scheduler/condition"
+	"github.com/mandelsoft/jobscheduler/syncutils"
 	"github.com/mandelsoft/jobscheduler/syncutils/synclog"
 )
 
@@ -30,6 +33,20 @@ type scheduler struct {
 	cancel    context.CancelFunc
 	extension Extension
 
+	extensionErr error
+	errors       errors.ErrorList
+	jobs         *syncutils.WaitGroup
+	closed       bool
+
+	processorCount int
+
+	recoverPanics atomic.Bool
+	handlers      []SchedulerEventHandler
+	cancelled     bool
+	watching      bool
+	deadlock      deadlockDetector
+	heartbeats    int
+
 	name       string
 	numRange   atomic.Uint64
 	jobRange   atomic.Uint64
@@ -71,14 +88,67 @@ func New(name ...string) Scheduler {
 		failed:    newFinalState(FAILED),
 		discarded: newFinalState(DISCARDED),
 		limiter:   l,
+		jobs:      syncutils.NewWaitGroup(),
 	}
+	s.recoverPanics.Store(true)
+	s.extension.Setup(s)
 	s.processors = processors.NewProcessors[*job](s.create, s.limiter)
 	s.processors.SetStateHandler(&stateHandler{s})
 	return s
 }
 
 func (s *scheduler) SetExtension(e Extension) {
+	err := e.Setup(s)
+	if err != nil {
+		log.Error("setup of extension failed", "scheduler", s.name, "error", err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
 	s.extension = e
+	s.extensionErr = err
+}
+
+func (s *scheduler) addError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.errors.Add(err)
+}
+
+func (s *scheduler) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	list := errors.ErrListf("shutdown of %s", s.name)
+	list.Add(s.jobs.Wait(ctx))
+
+	s.Cancel()
+	s.lock.Lock()
+	if s.cancel != nil {
+		// cancel the jobs still running after the timeout.
+		s.cancel()
+	}
+	s.lock.Unlock()
+	s.Wait()
+
+	s.lock.Lock()
+	ext := s.extension
+	closed := s.closed
+	s.closed = true
+	list.Add(s.errors.Entries()...)
+	s.errors.Clear()
+	s.lock.Unlock()
+
+	if !closed {
+		if err := ext.Close(); err != nil {
+			list.Add(errors.Wrapf(err, "closing extension"))
+		}
+	}
+	return list.Result()
+}
+
+func (s *scheduler) SetPanicRecovery(enabled bool) {
+	s.recoverPanics.Store(enabled)
 }
 
 func (s *scheduler) GetName() string {
@@ -90,6 +160,10 @@ func (s *scheduler) GetPool() processors.Pool {
 }
 
 func (s *scheduler) Cancel() {
+	s.lock.Lock()
+	s.cancelled = true
+	s.lock.Unlock()
+
 	s.pending.Monitor().Lock()
 	defer s.pending.Monitor().Unlock()
 
@@ -101,27 +175,61 @@ func (s *scheduler) Wait() {
 }
 
 func (s *scheduler) AddProcessor(n ...int) {
+	cnt := 0
 	if len(n) == 0 {
 		s.processors.New()
+		cnt++
 	} else {
 		for _, c := range n {
 			for i := 0; i < c; i++ {
 				s.processors.New()
+				cnt++
 			}
 		}
 	}
+	s.updateProcessorCount(cnt)
 }
 
 func (s *scheduler) RemoveProcessor(ctx context.Context) {
-	s.processors.Discard(ctx)
+	if s.processors.Discard(ctx) == nil {
+		s.updateProcessorCount(-1)
+	}
+}
+
+func (s *scheduler) GetProcessorCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.processorCount
+}
+
+func (s *scheduler) updateProcessorCount(delta int) {
+	s.lock.Lock()
+	s.processorCount += delta
+	cnt := s.processorCount
+	s.lock.Unlock()
+	s.raise(&ProcessorEvent{Count: cnt})
 }
 
 func (s *scheduler) Run(ctx context.Context) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	s.ctx = ctx
-	return s.processors.Run(setScheduler(ctx, s))
+	if s.IsStarted() {
+		return processors.ErrAlreadyStarted
+	}
+	s.lock.Lock()
+	if s.extensionErr != nil {
+		s.lock.Unlock()
+		return errors.Wrapf(s.extensionErr, "extension setup")
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	s.lock.Unlock()
+	err := s.processors.Run(setScheduler(s.ctx, s))
+	if err != nil {
+		return err
+	}
+	s.watch()
+	return nil
 }
 
 func (s *scheduler) create(id int) processors.Runner {
@@ -249,6 +357,11 @@ func (s *scheduler) Apply(def JobDefinition, parent ...Job) (Job, error) {
 	} else {
 		ctx = processors.WithPool(ctx, s.processors)
 	}
+	w := ext.Writer()
+	if w == nil {
+		w = io.Discard
+	}
+
 	ctx, cancel := context.WithCancel(ctx)
 	j := &job{
 		lock:       synclog.NewMutex(fmt.Sprintf("job %s", id)),
@@ -262,7 +375,7 @@ func (s *scheduler) Apply(def JobDefinition, parent ...Job) (Job, error) {
 		parent:     p,
 		ctx:        ctx,
 		cancel:     cancel,
-		writer:     ext.Writer(),
+		writer:     w,
 	}
 	if p != nil {
 		p.children = append(p.children, j)
@@ -279,7 +392,7 @@ type stateHandler struct {
 	scheduler *scheduler
 }
 
-var _ processors.StateHandler = (*stateHandler)(nil)
+var _ processors.BlockingStateHandler = (*stateHandler)(nil)
 
 func (s *stateHandler) Ready(ctx context.Context) {
 	GetJob(ctx).(*job).SetState(s.scheduler.ready)
@@ -292,3 +405,10 @@ func (s *stateHandler) Running(ctx context.Context) {
 func (s *stateHandler) Block(ctx context.Context) {
 	GetJob(ctx).(*job).SetState(s.scheduler.blocked)
 }
+
+func (s *stateHandler) BlockOn(ctx context.Context, obj any) {
+	j := GetJob(ctx).(*job)
+	j.lock.Lock()
+	j.blockedOn = obj
+	j.setState(s.scheduler.blocked)
+}
@@ -2,8 +2,11 @@ package scheduler_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"sync"
+	"time"
 
 	. "github.com/mandelsoft/goutils/testutils"
 	. "github.com/onsi/ginkgo/v2"
@@ -11,6 +14,7 @@ import (
 
 	"github.com/mandelsoft/jobscheduler/processors"
 	"github.com/mandelsoft/jobscheduler/scheduler"
+	"github.com/mandelsoft/jobscheduler/scheduler/condition"
 )
 
 type JobHandler struct {
@@ -37,6 +41,45 @@ func EVT(name string, state scheduler.State) string {
 	return fmt.Sprintf("%s:%s", name, state)
 }
 
+type TestExtension struct {
+	setupErr    error
+	closeErr    error
+	jobCloseErr error
+	setup       bool
+	closed      int
+}
+
+func (e *TestExtension) GetExtension(typ string) scheduler.Extension {
+	return nil
+}
+
+func (e *TestExtension) Setup(s scheduler.Scheduler) error {
+	e.setup = true
+	return e.setupErr
+}
+
+func (e *TestExtension) JobExtension(id string, definition scheduler.JobDefinition, parent scheduler.Job) (scheduler.JobExtension, error) {
+	return &TestJobExtension{closeErr: e.jobCloseErr}, nil
+}
+
+func (e *TestExtension) Close() error {
+	e.closed++
+	return e.closeErr
+}
+
+type TestJobExtension struct {
+	closeErr error
+}
+
+func (j *TestJobExtension) GetExtension(typ string) scheduler.JobExtension { return nil }
+func (j *TestJobExtension) HandleJobEvent(e scheduler.JobEvent)            {}
+func (j *TestJobExtension) Writer() io.Writer                              { return nil }
+func (j *TestJobExtension) Start()                                         {}
+func (j *TestJobExtension) SetState(state scheduler.State)                 {}
+func (j *TestJobExtension) Heartbeat(info any)                             {}
+func (j *TestJobExtension) Progress(info scheduler.ProgressInfo)           {}
+func (j *TestJobExtension) Close() error                                   { return j.closeErr }
+
 var _ = Describe("Scheduler Test Environment", func() {
 	// logging.DefaultContext().SetBaseLogger(logrusl.Human(true).NewLogr())
 	// logging.DefaultContext().SetDefaultLevel(logging.TraceLevel)
@@ -76,6 +119,77 @@ var _ = Describe("Scheduler Test Environment", func() {
 			Expect(handler.events).To(Equal(EVTs(id, scheduler.PENDING, scheduler.RUNNING, scheduler.DONE)))
 		})
 
+		It("records timeline", func() {
+			def := scheduler.DefineJob("test",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					time.Sleep(100 * time.Millisecond)
+					return nil, nil
+				}))
+			job := Must(sched.Apply(def, nil))
+			MustBeSuccessful(job.Schedule())
+			job.Wait()
+
+			tl := job.GetTimeline()
+			var states []scheduler.State
+			for _, e := range tl {
+				states = append(states, e.State)
+			}
+			Expect(states).To(Equal([]scheduler.State{scheduler.INITIAL, scheduler.PENDING, scheduler.RUNNING, scheduler.DONE}))
+			Expect(tl.RunTime()).To(BeNumerically(">=", 100*time.Millisecond))
+			Expect(tl.BlockedTime()).To(Equal(time.Duration(0)))
+			Expect(tl.Elapsed()).To(BeNumerically(">=", tl.RunTime()))
+			Expect(tl.Finished()).NotTo(BeZero())
+		})
+
+		It("reports progress", func() {
+			def := scheduler.DefineJob("test",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					p := ctx.Progress()
+					p.SetTotal(10)
+					p.Inc()
+					p.Inc(2)
+					p.SetMessage("working")
+					return nil, nil
+				}))
+			job := Must(sched.ScheduleDefinition(def))
+			job.Wait()
+
+			Expect(job.GetProgress()).To(Equal(scheduler.ProgressInfo{Total: 10, Current: 3, Message: "working"}))
+			Expect(job.GetProgress().Percent()).To(Equal(30.0))
+		})
+
+		It("recovers panic", func() {
+			id := "panic[1]"
+			handler := &JobHandler{}
+
+			def := scheduler.DefineJob("panic",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					panic("test panic")
+				}))
+			job1 := Must(sched.Apply(def, nil))
+			job1.RegisterHandler(handler)
+			job2 := Must(sched.Apply(scheduler.DefineJob("test",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					return "done", nil
+				})), nil))
+			MustBeSuccessful(job1.Schedule())
+			MustBeSuccessful(job2.Schedule())
+			job1.Wait()
+			job2.Wait()
+
+			// events are reported asynchronously and may be delivered out of order.
+			Expect(job1.GetState()).To(Equal(scheduler.FAILED))
+			Expect(handler.events).To(ConsistOf(EVTs(id, scheduler.PENDING, scheduler.RUNNING, scheduler.FAILED)))
+			_, err := job1.GetResult()
+			var perr *scheduler.PanicError
+			Expect(errors.As(err, &perr)).To(BeTrue())
+			Expect(perr.Value).To(Equal("test panic"))
+			Expect(string(perr.Stack)).To(ContainSubstring("scheduler_test"))
+
+			Expect(job2.GetState()).To(Equal(scheduler.DONE))
+			Expect(Must(job2.GetResult())).To(Equal("done"))
+		})
+
 		It("processes sequence", func() {
 			id1 := "test[1]"
 			id2 := "test[2]"
@@ -161,6 +275,230 @@ var _ = Describe("Scheduler Test Environment", func() {
 		})
 	})
 
+	Context("deadlock detection", func() {
+		BeforeEach(func() {
+			sched.AddProcessor()
+			sched.Run(nil)
+		})
+
+		It("reports blocked jobs", func() {
+			var lock sync.Mutex
+			var events []scheduler.DeadlockEvent
+
+			sched.SetDeadlockDetection(200*time.Millisecond, scheduler.DeadlockHandlerFunc(func(e scheduler.DeadlockEvent) {
+				lock.Lock()
+				defer lock.Unlock()
+				events = append(events, e)
+			}))
+
+			wg := processors.NewWaitGroup()
+			wg.Add(1)
+			def := scheduler.DefineJob("test",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					return nil, wg.Wait(ctx)
+				}))
+			job := Must(sched.ScheduleDefinition(def))
+
+			Eventually(func() int {
+				lock.Lock()
+				defer lock.Unlock()
+				return len(events)
+			}, 2*time.Second).Should(Equal(1))
+
+			Expect(events[0].Jobs).To(HaveLen(1))
+			Expect(events[0].Jobs[0].Job).To(BeIdenticalTo(job))
+			Expect(events[0].Jobs[0].State).To(Equal(scheduler.BLOCKED))
+			Expect(events[0].Jobs[0].WaitingFor).To(BeIdenticalTo(wg))
+			Expect(events[0].String()).To(ContainSubstring("test[1][blocked] waiting for *processors.WaitGroup"))
+
+			time.Sleep(300 * time.Millisecond)
+			wg.Done()
+			job.Wait()
+			Expect(events).To(HaveLen(1))
+		})
+
+		It("ignores sleeping jobs", func() {
+			var lock sync.Mutex
+			var events []scheduler.DeadlockEvent
+
+			sched.SetDeadlockDetection(100*time.Millisecond, scheduler.DeadlockHandlerFunc(func(e scheduler.DeadlockEvent) {
+				lock.Lock()
+				defer lock.Unlock()
+				events = append(events, e)
+			}))
+
+			wg := processors.NewWaitGroup()
+			wg.Add(1)
+			waiting := Must(sched.ScheduleDefinition(scheduler.DefineJob("waiting",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					return nil, wg.Wait(ctx)
+				}))))
+			sleeping := Must(sched.ScheduleDefinition(scheduler.DefineJob("sleeping",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					err := processors.Sleep(ctx, 500*time.Millisecond)
+					wg.Done()
+					return nil, err
+				}))))
+
+			sleeping.Wait()
+			waiting.Wait()
+			lock.Lock()
+			defer lock.Unlock()
+			Expect(events).To(BeEmpty())
+		})
+
+		It("ignores jobs waiting for a condition", func() {
+			var lock sync.Mutex
+			var events []scheduler.DeadlockEvent
+
+			sched.SetDeadlockDetection(100*time.Millisecond, scheduler.DeadlockHandlerFunc(func(e scheduler.DeadlockEvent) {
+				lock.Lock()
+				defer lock.Unlock()
+				events = append(events, e)
+			}))
+
+			cond := condition.Explicit()
+			job := Must(sched.ScheduleDefinition(scheduler.DefineJob("waiting",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					return nil, nil
+				})).SetCondition(cond)))
+			Expect(job.GetState()).To(Equal(scheduler.WAITING))
+			time.AfterFunc(500*time.Millisecond, func() { cond.Enable() })
+
+			job.Wait()
+			lock.Lock()
+			defer lock.Unlock()
+			Expect(events).To(BeEmpty())
+		})
+	})
+
+	Context("stall detection", func() {
+		BeforeEach(func() {
+			sched.AddProcessor()
+			sched.Run(nil)
+		})
+
+		It("reports and cancels stalled job", func() {
+			id := "test[1]"
+			handler := &JobHandler{}
+
+			def := scheduler.DefineJob("test",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					for i := 0; i < 5; i++ {
+						time.Sleep(20 * time.Millisecond)
+						ctx.Heartbeat(i)
+					}
+					for {
+						select {
+						case <-ctx.Done():
+							return nil, ctx.Err()
+						default:
+							time.Sleep(10 * time.Millisecond)
+						}
+					}
+				})).SetHeartbeatTimeout(100 * time.Millisecond).SetCancelOnStall()
+			job := Must(sched.Apply(def))
+			job.RegisterHandler(handler)
+			MustBeSuccessful(job.Schedule())
+			job.Wait()
+
+			// events are reported asynchronously and may be delivered out of order.
+			Expect(job.GetState()).To(Equal(scheduler.FAILED))
+			Expect(handler.events).To(ConsistOf(EVTs(id, scheduler.PENDING, scheduler.RUNNING, scheduler.STALLED, scheduler.FAILED)))
+			_, err := job.GetResult()
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+
+	Context("extension lifecycle", func() {
+		It("reports setup errors", func() {
+			ext := &TestExtension{setupErr: fmt.Errorf("setup failed")}
+			sched.SetExtension(ext)
+			sched.AddProcessor()
+			Expect(sched.Run(nil)).To(MatchError("extension setup: setup failed"))
+
+			ext.setupErr = nil
+			sched.SetExtension(ext)
+			MustBeSuccessful(sched.Run(nil))
+		})
+
+		It("closes extensions on shutdown", func() {
+			ext := &TestExtension{jobCloseErr: fmt.Errorf("job close failed")}
+			sched.SetExtension(ext)
+			sched.AddProcessor()
+			MustBeSuccessful(sched.Run(nil))
+
+			def := scheduler.DefineJob("test",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					return nil, nil
+				}))
+			job := Must(sched.Apply(def))
+			MustBeSuccessful(job.Schedule())
+			_ = Must(sched.ScheduleDefinition(scheduler.DefineJob("blocking",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}))))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			err := sched.Shutdown(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("job test[1]: job close failed"))
+			Expect(ext.setup).To(BeTrue())
+			Expect(ext.closed).To(Equal(1))
+		})
+
+		It("closes extension once", func() {
+			ext := &TestExtension{closeErr: fmt.Errorf("close failed")}
+			sched.SetExtension(ext)
+			sched.AddProcessor()
+			MustBeSuccessful(sched.Run(nil))
+
+			Expect(sched.Shutdown(context.Background())).To(MatchError(ContainSubstring("closing extension: close failed")))
+			MustBeSuccessful(sched.Shutdown(context.Background()))
+			Expect(ext.closed).To(Equal(1))
+		})
+
+		It("does not cancel running jobs on cancel", func() {
+			sched.AddProcessor()
+			MustBeSuccessful(sched.Run(nil))
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			job := Must(sched.ScheduleDefinition(scheduler.DefineJob("test",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					close(started)
+					<-release
+					return nil, ctx.Err()
+				}))))
+			<-started
+			sched.Cancel()
+			close(release)
+			job.Wait()
+			Expect(job.GetState()).To(Equal(scheduler.DONE))
+		})
+
+		It("ignores unscheduled jobs on shutdown", func() {
+			sched.AddProcessor()
+			MustBeSuccessful(sched.Run(nil))
+
+			def := scheduler.DefineJob("test",
+				scheduler.RunnerFunc(func(ctx scheduler.SchedulingContext) (scheduler.Result, error) {
+					return nil, nil
+				}))
+			job := Must(sched.ScheduleDefinition(def))
+			initial := Must(sched.Apply(def))
+
+			MustBeSuccessful(sched.Shutdown(context.Background()))
+			Expect(job.GetState()).To(Equal(scheduler.DONE))
+			Expect(initial.GetState()).To(Equal(scheduler.INITIAL))
+			// waiting for an unscheduled job does not block.
+			initial.Wait()
+		})
+	})
+
 	Context("sync operations", func() {
 		var barrier *Barrier
 
@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"slices"
+	"time"
+)
+
+func (j *job) heartbeat(info any) {
+	j.lock.Lock()
+	j.lastBeat = time.Now()
+	j.stalled = false
+	ext := j.extension
+	j.lock.Unlock()
+
+	ext.Heartbeat(info)
+}
+
+func (s *scheduler) addHeartbeatJob() {
+	s.lock.Lock()
+	s.heartbeats++
+	s.lock.Unlock()
+	s.watch()
+}
+
+func (s *scheduler) removeHeartbeatJob() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.heartbeats--
+}
+
+// checkStalled is called periodically by the watchdog.
+// It reports running jobs, which missed their
+// heartbeat timeout, once per stall.
+func (s *scheduler) checkStalled(now time.Time) {
+	var stalled []*job
+
+	for j := range s.running.Elements() {
+		if j.definition.heartbeat <= 0 {
+			continue
+		}
+		j.lock.Lock()
+		if !j.stalled && j.state == s.running && now.Sub(j.lastBeat) > j.definition.heartbeat {
+			j.stalled = true
+			stalled = append(stalled, j)
+		}
+		j.lock.Unlock()
+	}
+
+	for _, j := range stalled {
+		j.lock.Lock()
+		e := JobEvent{job: j, state: STALLED, time: now, timeline: slices.Clone(j.timeline)}
+		handlers := slices.Clone(j.handlers)
+		j.lock.Unlock()
+
+		log.Warn("job {{job}} stalled", "job", j.id, "scheduler", s.name, "timeout", j.definition.heartbeat)
+		j.extension.HandleJobEvent(e)
+		for _, h := range handlers {
+			h.HandleJobEvent(e)
+		}
+		s.raise(e)
+		if j.definition.cancel {
+			log.Warn("cancel stalled job {{job}}", "job", j.id, "scheduler", s.name)
+			j.cancel()
+		}
+	}
+}
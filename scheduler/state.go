@@ -15,6 +15,7 @@ type stateJobs interface {
 	Add(*job)
 	Remove(*job)
 	State() State
+	Len() int
 }
 
 type pendingState struct {
@@ -56,9 +57,19 @@ func (s *generalState) Remove(j *job) {
 	s.set.Delete(j)
 }
 
+func (s *generalState) Len() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.set)
+}
+
 func (s *generalState) Elements() iter.Seq[*job] {
 	return func(yield func(*job) bool) {
-		for v := range maps.Clone(s.set) {
+		s.lock.Lock()
+		set := maps.Clone(s.set)
+		s.lock.Unlock()
+		for v := range set {
 			if !yield(v) {
 				return
 			}
@@ -88,6 +99,10 @@ func (s *finalState) Add(j *job) {
 func (s *finalState) Remove(j *job) {
 }
 
+func (s *finalState) Len() int {
+	return 0
+}
+
 func (s *finalState) Elements() iter.Seq[*job] {
 	return func(yield func(*job) bool) {
 	}
@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// TimelineEntry describes a state transition of a job
+// and the time it happened.
+type TimelineEntry struct {
+	State State     `json:"state"`
+	Time  time.Time `json:"time"`
+}
+
+// Timeline is the sequence of state transitions of a job.
+type Timeline []TimelineEntry
+
+// Applied returns the time the job has been created.
+func (t Timeline) Applied() time.Time {
+	return t.First(INITIAL)
+}
+
+// Scheduled returns the time the job has been scheduled.
+func (t Timeline) Scheduled() time.Time {
+	for _, e := range t {
+		if e.State != INITIAL {
+			return e.Time
+		}
+	}
+	return time.Time{}
+}
+
+// Started returns the time the job got its first processor.
+func (t Timeline) Started() time.Time {
+	return t.First(RUNNING)
+}
+
+// Finished returns the time the job reached a final state.
+func (t Timeline) Finished() time.Time {
+	for _, e := range t {
+		if IsFinished(e.State) {
+			return e.Time
+		}
+	}
+	return time.Time{}
+}
+
+// First returns the time the given state has been reached first.
+// If the state has never been reached the zero time is returned.
+func (t Timeline) First(state State) time.Time {
+	for _, e := range t {
+		if e.State == state {
+			return e.Time
+		}
+	}
+	return time.Time{}
+}
+
+// Last returns the last state transition.
+func (t Timeline) Last() (TimelineEntry, bool) {
+	if len(t) == 0 {
+		return TimelineEntry{}, false
+	}
+	return t[len(t)-1], true
+}
+
+// Duration returns the accumulated time spent in the given states.
+// If the job is not yet finished, the time spent in the
+// actual state is calculated up to now.
+func (t Timeline) Duration(states ...State) time.Duration {
+	var d time.Duration
+
+	for i, e := range t {
+		match := false
+		for _, s := range states {
+			if e.State == s {
+				match = true
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		if i+1 < len(t) {
+			d += t[i+1].Time.Sub(e.Time)
+		} else {
+			if !IsFinished(e.State) {
+				d += time.Since(e.Time)
+			}
+		}
+	}
+	return d
+}
+
+// WaitTime returns the time spent waiting for the start condition.
+func (t Timeline) WaitTime() time.Duration {
+	return t.Duration(WAITING)
+}
+
+// QueueTime returns the time spent waiting for a processor,
+// either to get started or to continue after being blocked.
+func (t Timeline) QueueTime() time.Duration {
+	return t.Duration(PENDING, READY)
+}
+
+// BlockedTime returns the time spent in synchronization operations.
+func (t Timeline) BlockedTime() time.Duration {
+	return t.Duration(BLOCKED)
+}
+
+// RunTime returns the time a processor has been assigned to the job.
+func (t Timeline) RunTime() time.Duration {
+	return t.Duration(RUNNING)
+}
+
+// ZombieTime returns the time spent waiting for sub jobs
+// after the job's runner has finished.
+func (t Timeline) ZombieTime() time.Duration {
+	return t.Duration(ZOMBIE)
+}
+
+// Elapsed returns the time between scheduling the job and reaching a
+// final state. If the job is not yet finished, the time up to
+// now is returned.
+func (t Timeline) Elapsed() time.Duration {
+	s := t.Scheduled()
+	if s.IsZero() {
+		return 0
+	}
+	f := t.Finished()
+	if f.IsZero() {
+		return time.Since(s)
+	}
+	return f.Sub(s)
+}
@@ -0,0 +1,56 @@
+package scheduler
+
+import (
+	"time"
+)
+
+// watchInterval is the interval used by the watchdog
+// to check the job states.
+var watchInterval = 50 * time.Millisecond
+
+// watch starts the watchdog, if the scheduler is started
+// and any watchdog functionality is enabled.
+// The watchdog terminates, if the scheduler is cancelled or
+// no watchdog functionality is enabled anymore.
+func (s *scheduler) watch() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.watching || s.ctx == nil || s.cancelled || !s.needsWatchdog() {
+		return
+	}
+	s.watching = true
+	go s.watchdog()
+}
+
+func (s *scheduler) needsWatchdog() bool {
+	return s.deadlock.period > 0 || s.heartbeats > 0
+}
+
+func (s *scheduler) watchdog() {
+	log.Debug("starting watchdog", "scheduler", s.name)
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			s.lock.Lock()
+			s.watching = false
+			s.lock.Unlock()
+			log.Debug("watchdog cancelled", "scheduler", s.name)
+			return
+		case now := <-ticker.C:
+			s.lock.Lock()
+			if s.cancelled || !s.needsWatchdog() {
+				s.watching = false
+				s.lock.Unlock()
+				log.Debug("watchdog stopped", "scheduler", s.name)
+				return
+			}
+			s.lock.Unlock()
+			s.checkDeadlock(now)
+			s.checkStalled(now)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package syncutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mandelsoft/jobscheduler/syncutils/utils"
+)
+
+// Barrier is a cyclic barrier for a fixed number of parties.
+// The Go routines calling Wait are blocked until all
+// parties have reached the barrier. Afterwards, the barrier
+// is reset and can be used for the next cycle.
+type Barrier interface {
+	// Wait blocks until all parties have called Wait or the
+	// context is cancelled. A cancelled party is removed
+	// from the actual cycle.
+	Wait(ctx context.Context) error
+
+	// Parties returns the number of parties required to trip the barrier.
+	Parties() int
+	// Waiting returns the number of parties actually waiting.
+	Waiting() int
+}
+
+type barrier struct {
+	lock       sync.Mutex
+	parties    int
+	count      int
+	generation int
+	waiting    utils.Waiting
+}
+
+var _ Barrier = (*barrier)(nil)
+
+// NewBarrier creates a new cyclic Barrier for n parties.
+func NewBarrier(n int, h ...utils.WaitingHandler) Barrier {
+	if n <= 0 {
+		panic("barrier requires at least one party")
+	}
+	return &barrier{parties: n, waiting: utils.NewWaiting(h...)}
+}
+
+func (b *barrier) Wait(ctx context.Context) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	gen := b.generation
+	b.count++
+	if b.count == b.parties {
+		b.count = 0
+		b.generation++
+		b.waiting.SignalAll()
+		return nil
+	}
+	for gen == b.generation {
+		err := b.waiting.Wait(ctx, &b.lock)
+		if err != nil {
+			if gen == b.generation {
+				b.count--
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *barrier) Parties() int {
+	return b.parties
+}
+
+func (b *barrier) Waiting() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.count
+}
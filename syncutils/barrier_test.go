@@ -0,0 +1,161 @@
+package syncutils_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/syncutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("barrier Test Environment", func() {
+	var b syncutils.Barrier
+
+	BeforeEach(func() {
+		b = syncutils.NewBarrier(3)
+	})
+
+	It("trips", func(ctx SpecContext) {
+		var passed atomic.Int32
+		wg := syncutils.NewWaitGroup()
+		wg.Add(2)
+		for i := 0; i < 2; i++ {
+			go func() {
+				defer GinkgoRecover()
+				MustBeSuccessful(b.Wait(ctx))
+				passed.Add(1)
+				wg.Done()
+			}()
+		}
+		time.Sleep(100 * time.Millisecond)
+		Expect(b.Waiting()).To(Equal(2))
+		Expect(passed.Load()).To(Equal(int32(0)))
+		MustBeSuccessful(b.Wait(ctx))
+		MustBeSuccessful(wg.Wait(ctx))
+		Expect(b.Waiting()).To(Equal(0))
+	}, SpecTimeout(time.Second))
+
+	It("is cyclic", func(ctx SpecContext) {
+		var steps [3]int
+		wg := syncutils.NewWaitGroup()
+		wg.Add(3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				defer GinkgoRecover()
+				for s := 0; s < 5; s++ {
+					steps[i] = s
+					MustBeSuccessful(b.Wait(ctx))
+					for _, o := range steps {
+						Expect(o >= s).To(BeTrue())
+					}
+					MustBeSuccessful(b.Wait(ctx))
+				}
+				wg.Done()
+			}()
+		}
+		MustBeSuccessful(wg.Wait(ctx))
+		Expect(steps).To(Equal([3]int{4, 4, 4}))
+	}, SpecTimeout(time.Second))
+
+	It("timeout", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		ExpectError(b.Wait(ctx)).To(MatchError(context.DeadlineExceeded))
+		Expect(b.Waiting()).To(Equal(0))
+	})
+})
+
+var _ = Describe("latch Test Environment", func() {
+	var l syncutils.CountDownLatch
+
+	BeforeEach(func() {
+		l = syncutils.NewCountDownLatch(2)
+	})
+
+	It("waits for count down", func(ctx SpecContext) {
+		now := time.Now()
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			l.CountDown()
+			time.Sleep(100 * time.Millisecond)
+			l.CountDown()
+		}()
+		MustBeSuccessful(l.Wait(ctx))
+		Expect(time.Now().Sub(now)).To(BeNumerically(">", 200*time.Millisecond))
+		Expect(l.Count()).To(Equal(0))
+
+		l.CountDown()
+		Expect(l.Count()).To(Equal(0))
+		MustBeSuccessful(l.Wait(ctx))
+	}, SpecTimeout(time.Second))
+
+	It("timeout", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		l.CountDown()
+		ExpectError(l.Wait(ctx)).To(MatchError(context.DeadlineExceeded))
+	})
+})
+
+var _ = Describe("phaser Test Environment", func() {
+	var p syncutils.Phaser
+
+	BeforeEach(func() {
+		p = syncutils.NewPhaser(1)
+	})
+
+	It("advances", func(ctx SpecContext) {
+		Expect(p.Arrive()).To(Equal(0))
+		Expect(p.Phase()).To(Equal(1))
+
+		Expect(p.Register()).To(Equal(1))
+		Expect(p.Arrive()).To(Equal(1))
+		Expect(p.Arrived()).To(Equal(1))
+		Expect(p.Phase()).To(Equal(1))
+		Expect(p.Arrive()).To(Equal(1))
+		Expect(p.Phase()).To(Equal(2))
+	})
+
+	It("registers dynamically", func(ctx SpecContext) {
+		wg := syncutils.NewWaitGroup()
+		for i := 0; i < 3; i++ {
+			p.Register()
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				for s := 0; s < i+1; s++ {
+					Expect(p.ArriveAndAwaitAdvance(ctx)).To(Equal(s + 1))
+				}
+				p.ArriveAndDeregister()
+				wg.Done()
+			}()
+		}
+		for s := 0; s < 5; s++ {
+			Expect(p.ArriveAndAwaitAdvance(ctx)).To(Equal(s + 1))
+		}
+		MustBeSuccessful(wg.Wait(ctx))
+		Expect(p.Parties()).To(Equal(1))
+	}, SpecTimeout(time.Second))
+
+	It("awaits advance", func(ctx SpecContext) {
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			p.Arrive()
+		}()
+		Expect(p.AwaitAdvance(ctx, 0)).To(Equal(1))
+		Expect(p.AwaitAdvance(ctx, 0)).To(Equal(1))
+		p.Deregister()
+		Expect(p.Phase()).To(Equal(2))
+	}, SpecTimeout(time.Second))
+
+	It("timeout", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		p.Register()
+		ExpectError(p.ArriveAndAwaitAdvance(ctx)).To(MatchError(context.DeadlineExceeded))
+		Expect(p.Arrived()).To(Equal(1))
+	})
+})
@@ -0,0 +1,61 @@
+package syncutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mandelsoft/jobscheduler/syncutils/utils"
+)
+
+// Cond is a condition variable with a context-aware Wait.
+// Several Conds may share the same lock to provide
+// multiple wait queues for a single synchronized data structure.
+type Cond interface {
+	// Wait unlocks the lock of the condition and blocks the
+	// Go routine until it is signalled or the context is cancelled.
+	// It must be called under the lock, which is locked again before
+	// Wait returns, also in the error case.
+	// Because the condition may have changed again before the lock
+	// is re-acquired, it should always be checked in a loop.
+	Wait(ctx context.Context) error
+
+	// Signal deblocks the first waiting Go routine.
+	// It returns false, if no one is found.
+	Signal() bool
+
+	// Broadcast deblocks all waiting Go routines.
+	// It returns false, if no one is found.
+	Broadcast() bool
+
+	// L returns the lock of the condition.
+	L() sync.Locker
+}
+
+type cond struct {
+	lock    sync.Locker
+	waiting utils.Waiting
+}
+
+var _ Cond = (*cond)(nil)
+
+// NewCond creates a new Cond for the given lock.
+// Signal and Broadcast should be called under this lock.
+func NewCond(l sync.Locker, h ...utils.WaitingHandler) Cond {
+	return &cond{lock: l, waiting: utils.NewWaiting(h...)}
+}
+
+func (c *cond) Wait(ctx context.Context) error {
+	return c.waiting.Wait(ctx, c.lock)
+}
+
+func (c *cond) Signal() bool {
+	return c.waiting.SignalNext()
+}
+
+func (c *cond) Broadcast() bool {
+	return c.waiting.SignalAll()
+}
+
+func (c *cond) L() sync.Locker {
+	return c.lock
+}
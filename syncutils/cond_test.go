@@ -0,0 +1,107 @@
+package syncutils_test
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/syncutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Cond Test Environment", func() {
+	var lock sync.Mutex
+	var cond syncutils.Cond
+
+	BeforeEach(func() {
+		cond = syncutils.NewCond(&lock)
+	})
+
+	It("signals", func(ctx SpecContext) {
+		ready := false
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			lock.Lock()
+			ready = true
+			cond.Signal()
+			lock.Unlock()
+		}()
+		lock.Lock()
+		for !ready {
+			MustBeSuccessful(cond.Wait(ctx))
+		}
+		lock.Unlock()
+	}, SpecTimeout(time.Second))
+
+	It("broadcasts", func(ctx SpecContext) {
+		ready := false
+		wg := syncutils.NewWaitGroup()
+		wg.Add(3)
+		for i := 0; i < 3; i++ {
+			go func() {
+				defer GinkgoRecover()
+				lock.Lock()
+				for !ready {
+					MustBeSuccessful(cond.Wait(ctx))
+				}
+				lock.Unlock()
+				wg.Done()
+			}()
+		}
+		time.Sleep(100 * time.Millisecond)
+		lock.Lock()
+		ready = true
+		Expect(cond.Broadcast()).To(BeTrue())
+		lock.Unlock()
+		MustBeSuccessful(wg.Wait(ctx))
+	}, SpecTimeout(time.Second))
+
+	It("timeout", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		lock.Lock()
+		ExpectError(cond.Wait(ctx)).To(MatchError(context.DeadlineExceeded))
+		Expect(lock.TryLock()).To(BeFalse())
+		Expect(cond.Signal()).To(BeFalse())
+		lock.Unlock()
+	})
+
+	It("uses multiple queues", func(ctx SpecContext) {
+		notEmpty := cond
+		notFull := syncutils.NewCond(&lock)
+
+		var buf []int
+		wg := syncutils.NewWaitGroup()
+		wg.Add(1)
+		go func() {
+			defer GinkgoRecover()
+			for i := 0; i < 10; i++ {
+				lock.Lock()
+				for len(buf) == 2 {
+					MustBeSuccessful(notFull.Wait(ctx))
+				}
+				buf = append(buf, i)
+				notEmpty.Signal()
+				lock.Unlock()
+			}
+			wg.Done()
+		}()
+
+		var result []int
+		for len(result) < 10 {
+			lock.Lock()
+			for len(buf) == 0 {
+				MustBeSuccessful(notEmpty.Wait(ctx))
+			}
+			result = append(result, buf[0])
+			buf = buf[1:]
+			notFull.Signal()
+			lock.Unlock()
+		}
+		MustBeSuccessful(wg.Wait(ctx))
+		Expect(result).To(Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}))
+	}, SpecTimeout(time.Second))
+})
@@ -0,0 +1,69 @@
+package syncutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mandelsoft/jobscheduler/syncutils/utils"
+)
+
+// CountDownLatch blocks Go routines until a
+// count has been counted down to zero.
+// In contrast to a Barrier it cannot be reused.
+type CountDownLatch interface {
+	// CountDown decrements the count. If it reaches
+	// zero, all waiting Go routines are deblocked.
+	CountDown()
+
+	// Count returns the actual count.
+	Count() int
+
+	// Wait blocks until the count reaches zero or
+	// the context is cancelled.
+	Wait(ctx context.Context) error
+}
+
+type countDownLatch struct {
+	lock    sync.Mutex
+	count   int
+	waiting utils.Waiting
+}
+
+var _ CountDownLatch = (*countDownLatch)(nil)
+
+// NewCountDownLatch creates a new CountDownLatch with the count n.
+func NewCountDownLatch(n int, h ...utils.WaitingHandler) CountDownLatch {
+	return &countDownLatch{count: n, waiting: utils.NewWaiting(h...)}
+}
+
+func (l *countDownLatch) CountDown() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.count == 0 {
+		return
+	}
+	l.count--
+	if l.count == 0 {
+		l.waiting.SignalAll()
+	}
+}
+
+func (l *countDownLatch) Count() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.count
+}
+
+func (l *countDownLatch) Wait(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for l.count > 0 {
+		err := l.waiting.Wait(ctx, &l.lock)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -37,17 +37,24 @@ type Monitor interface {
 	// not transfer the lock to any deblocked Go routine.
 	// Each routine acquires a lock separately.
 	SignalAll() bool
+
+	// NewCondition creates a new Monitor sharing the lock
+	// and the waiting handler with this monitor, but
+	// using a separate waiting list. It can be used to
+	// deblock selected groups of waiting Go routines.
+	NewCondition() Monitor
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 
 type monitor struct {
 	sync.Locker
+	handler []utils.WaitingHandler
 	waiting utils.Waiting
 }
 
 func NewMonitor(l sync.Locker, h ...utils.WaitingHandler) Monitor {
-	return &monitor{Locker: l, waiting: utils.NewWaiting(h...)}
+	return &monitor{Locker: l, handler: h, waiting: utils.NewWaiting(h...)}
 }
 
 func NewMutexMonitor(h ...utils.WaitingHandler) Monitor {
@@ -69,3 +76,7 @@ func (w *monitor) SignalAll() bool {
 func (w *monitor) HasWaiting() bool {
 	return w.waiting.HasWaiting()
 }
+
+func (w *monitor) NewCondition() Monitor {
+	return NewMonitor(w.Locker, w.handler...)
+}
@@ -0,0 +1,154 @@
+package syncutils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mandelsoft/jobscheduler/syncutils/utils"
+)
+
+// Phaser is a reusable barrier with a dynamic number of
+// registered parties. Every time all registered parties
+// have arrived, the phaser advances to the next phase.
+type Phaser interface {
+	// Register adds a new party and returns the actual phase.
+	Register() int
+	// Deregister removes a party without arriving.
+	// It returns the actual phase.
+	Deregister() int
+
+	// Arrive marks the arrival of a party at the actual phase
+	// without waiting for the others. It returns the arrival phase.
+	Arrive() int
+	// ArriveAndDeregister marks the arrival of a party and removes it.
+	// It returns the arrival phase.
+	ArriveAndDeregister() int
+	// ArriveAndAwaitAdvance marks the arrival of a party and
+	// waits for the other parties. It returns the new phase.
+	// If the context is cancelled, the arrival is kept.
+	ArriveAndAwaitAdvance(ctx context.Context) (int, error)
+
+	// AwaitAdvance waits until the phaser advances from the given phase.
+	// It returns the new phase. If the phaser is already
+	// in another phase, it returns immediately.
+	AwaitAdvance(ctx context.Context, phase int) (int, error)
+
+	// Phase returns the actual phase.
+	Phase() int
+	// Parties returns the number of registered parties.
+	Parties() int
+	// Arrived returns the number of parties arrived at the actual phase.
+	Arrived() int
+}
+
+type phaser struct {
+	lock    sync.Mutex
+	phase   int
+	parties int
+	arrived int
+	waiting utils.Waiting
+}
+
+var _ Phaser = (*phaser)(nil)
+
+// NewPhaser creates a new Phaser with n initially registered parties.
+func NewPhaser(n int, h ...utils.WaitingHandler) Phaser {
+	return &phaser{parties: n, waiting: utils.NewWaiting(h...)}
+}
+
+// check advances to the next phase, if all parties have arrived.
+// It must be called under the lock.
+func (p *phaser) check() {
+	if p.arrived >= p.parties {
+		p.arrived = 0
+		p.phase++
+		p.waiting.SignalAll()
+	}
+}
+
+func (p *phaser) Register() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.parties++
+	return p.phase
+}
+
+func (p *phaser) Deregister() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.parties == 0 {
+		panic("no registered party")
+	}
+	phase := p.phase
+	p.parties--
+	p.check()
+	return phase
+}
+
+func (p *phaser) arrive(deregister bool) int {
+	if p.arrived >= p.parties {
+		panic("no unarrived party")
+	}
+	phase := p.phase
+	if deregister {
+		p.parties--
+	} else {
+		p.arrived++
+	}
+	p.check()
+	return phase
+}
+
+func (p *phaser) Arrive() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.arrive(false)
+}
+
+func (p *phaser) ArriveAndDeregister() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.arrive(true)
+}
+
+func (p *phaser) ArriveAndAwaitAdvance(ctx context.Context) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.await(ctx, p.arrive(false))
+}
+
+func (p *phaser) AwaitAdvance(ctx context.Context, phase int) (int, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.await(ctx, phase)
+}
+
+func (p *phaser) await(ctx context.Context, phase int) (int, error) {
+	for p.phase == phase {
+		err := p.waiting.Wait(ctx, &p.lock)
+		if err != nil {
+			return p.phase, err
+		}
+	}
+	return p.phase, nil
+}
+
+func (p *phaser) Phase() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.phase
+}
+
+func (p *phaser) Parties() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.parties
+}
+
+func (p *phaser) Arrived() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.arrived
+}
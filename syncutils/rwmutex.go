@@ -2,6 +2,27 @@ package syncutils
 
 import (
 	"context"
+	"slices"
+
+	"github.com/mandelsoft/goutils/general"
+)
+
+// RWMutexPolicy describes how an RWMutex arbitrates
+// between waiting readers and writers.
+type RWMutexPolicy int
+
+const (
+	// READER_PREFERRING lets new readers in as long as
+	// there is no writer holding the lock (default).
+	// A continuous stream of readers may starve writers.
+	READER_PREFERRING RWMutexPolicy = iota
+	// WRITER_PREFERRING blocks new readers as long as
+	// there are waiting writers.
+	// A continuous stream of writers may starve readers.
+	WRITER_PREFERRING
+	// FAIR grants the lock in the order of the requests.
+	// Subsequent waiting readers are granted together.
+	FAIR
 )
 
 type RWMutex interface {
@@ -14,28 +35,161 @@ type RWMutex interface {
 	RLocker() Locker
 }
 
+// rwRequest is a waiting lock request.
+// It uses its own condition to be deblocked
+// without waking up other requests. Therefore,
+// a condition has at most one waiting Go routine.
+type rwRequest struct {
+	write bool
+	cond  Monitor
+}
+
 type rwMutex struct {
 	monitor Monitor
+	policy  RWMutexPolicy
 	locked  bool
 	readers int
+	writers int
+	queue   []*rwRequest
 }
 
 var _ RWMutex = (*rwMutex)(nil)
 
-func NewRWMutex() RWMutex {
-	return &rwMutex{monitor: NewMutexMonitor()}
+// NewRWMutex creates a new RWMutex using the optional
+// policy (default READER_PREFERRING).
+func NewRWMutex(policy ...RWMutexPolicy) RWMutex {
+	return NewRWMutex2(NewMutexMonitor(), policy...)
+}
+
+// NewRWMutex2 creates a new RWMutex based on the given Monitor
+// using the optional policy (default READER_PREFERRING).
+func NewRWMutex2(m Monitor, policy ...RWMutexPolicy) RWMutex {
+	return &rwMutex{monitor: m, policy: general.Optional(policy...)}
+}
+
+// first checks whether the given request is the next one to be granted.
+// A nil request is a new one, which is not queued yet.
+func (l *rwMutex) first(r *rwRequest) bool {
+	if r == nil {
+		return len(l.queue) == 0
+	}
+	return l.queue[0] == r
+}
+
+func (l *rwMutex) mayLock(r *rwRequest) bool {
+	if l.locked || l.readers > 0 {
+		return false
+	}
+	return l.policy != FAIR || l.first(r)
+}
+
+func (l *rwMutex) mayRLock(r *rwRequest) bool {
+	if l.locked {
+		return false
+	}
+	switch l.policy {
+	case WRITER_PREFERRING:
+		return l.writers == 0
+	case FAIR:
+		return l.first(r)
+	}
+	return true
+}
+
+func (l *rwMutex) enqueue(write bool) *rwRequest {
+	r := &rwRequest{write: write, cond: l.monitor.NewCondition()}
+	if write {
+		l.writers++
+	}
+	l.queue = append(l.queue, r)
+	return r
+}
+
+// dequeue removes a granted or cancelled request. Because
+// a cancelled request may enable other waiting requests,
+// the next requests are deblocked.
+func (l *rwMutex) dequeue(r *rwRequest) {
+	if r.write {
+		l.writers--
+	}
+	l.queue = slices.DeleteFunc(l.queue, func(e *rwRequest) bool { return e == r })
+	l.signal()
+}
+
+// signal deblocks the waiting requests, which may be granted
+// according to the policy: the first waiting writer or
+// the waiting readers.
+func (l *rwMutex) signal() {
+	if l.locked || len(l.queue) == 0 {
+		return
+	}
+	switch l.policy {
+	case READER_PREFERRING:
+		if !l.signalReaders(false) && l.readers == 0 {
+			l.signalWriter()
+		}
+	case WRITER_PREFERRING:
+		if l.writers == 0 {
+			l.signalReaders(false)
+		} else if l.readers == 0 {
+			l.signalWriter()
+		}
+	case FAIR:
+		if !l.queue[0].write {
+			l.signalReaders(true)
+		} else if l.readers == 0 {
+			l.signalWriter()
+		}
+	}
 }
 
-func NewRWMutex2(m Monitor) RWMutex {
-	return &rwMutex{monitor: m}
+// signalReaders deblocks the waiting readers. For a batch only
+// the readers up to the next waiting writer are deblocked.
+// It returns whether there are waiting readers.
+func (l *rwMutex) signalReaders(batch bool) bool {
+	found := false
+	for _, r := range l.queue {
+		if r.write {
+			if batch {
+				break
+			}
+			continue
+		}
+		found = true
+		r.cond.SignalAll()
+	}
+	return found
+}
+
+// signalWriter deblocks the first waiting writer.
+func (l *rwMutex) signalWriter() {
+	for _, r := range l.queue {
+		if r.write {
+			r.cond.SignalAll()
+			return
+		}
+	}
+}
+
+func (l *rwMutex) wait(ctx context.Context, write bool, cond func(r *rwRequest) bool) error {
+	r := l.enqueue(write)
+	defer l.dequeue(r)
+
+	for !cond(r) {
+		err := r.cond.Wait(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (l *rwMutex) Lock(ctx context.Context) error {
 	l.monitor.Lock()
 	defer l.monitor.Unlock()
 
-	if l.locked || l.readers > 0 {
-		err := l.monitor.Wait(ctx)
+	if !l.mayLock(nil) {
+		err := l.wait(ctx, true, l.mayLock)
 		if err != nil {
 			return err
 		}
@@ -48,7 +202,7 @@ func (l *rwMutex) TryLock() bool {
 	l.monitor.Lock()
 	defer l.monitor.Unlock()
 
-	if l.locked || l.readers > 0 {
+	if !l.mayLock(nil) {
 		return false
 	}
 	l.locked = true
@@ -57,20 +211,21 @@ func (l *rwMutex) TryLock() bool {
 
 func (l *rwMutex) Unlock() {
 	l.monitor.Lock()
+	defer l.monitor.Unlock()
+
 	if !l.locked {
-		l.monitor.Unlock()
 		panic("unlocking unlocked rwmutex")
 	}
 	l.locked = false
-	l.monitor.Signal(nil)
+	l.signal()
 }
 
 func (l *rwMutex) RLock(ctx context.Context) error {
 	l.monitor.Lock()
 	defer l.monitor.Unlock()
 
-	if l.locked {
-		err := l.monitor.Wait(ctx)
+	if !l.mayRLock(nil) {
+		err := l.wait(ctx, false, l.mayRLock)
 		if err != nil {
 			return err
 		}
@@ -83,7 +238,7 @@ func (l *rwMutex) TryRLock() bool {
 	l.monitor.Lock()
 	defer l.monitor.Unlock()
 
-	if l.locked {
+	if !l.mayRLock(nil) {
 		return false
 	}
 	l.readers++
@@ -92,15 +247,14 @@ func (l *rwMutex) TryRLock() bool {
 
 func (l *rwMutex) RUnlock() {
 	l.monitor.Lock()
+	defer l.monitor.Unlock()
+
 	if l.readers == 0 {
-		l.monitor.Unlock()
 		panic("unlocking unlocked rwmutex")
 	}
 	l.readers--
 	if l.readers == 0 {
-		l.monitor.Signal(nil)
-	} else {
-		l.monitor.Unlock()
+		l.signal()
 	}
 }
 
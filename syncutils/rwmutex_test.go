@@ -2,6 +2,7 @@ package syncutils_test
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	. "github.com/mandelsoft/goutils/testutils"
@@ -144,4 +145,187 @@ var _ = Describe("RWMutex Test Environment", func() {
 			Expect(lock.TryLock()).To(BeTrue())
 		}, SpecTimeout(2*time.Second))
 	})
+
+	Context("policies", func() {
+		// stress runs concurrent readers and writers for the given duration
+		// and returns the number of successful lock operations per Go routine.
+		stress := func(ctx context.Context, lock syncutils.RWMutex, readers, writers int, d time.Duration) ([]int, []int) {
+			r := make([]int, readers)
+			w := make([]int, writers)
+			end := time.Now().Add(d)
+			wg := syncutils.WaitGroup{}
+			wg.Add(readers + writers)
+			for i := range r {
+				go func() {
+					defer GinkgoRecover()
+					for time.Now().Before(end) {
+						MustBeSuccessful(lock.RLock(ctx))
+						r[i]++
+						time.Sleep(2 * time.Millisecond)
+						lock.RUnlock()
+					}
+					wg.Done()
+				}()
+			}
+			for i := range w {
+				go func() {
+					defer GinkgoRecover()
+					for time.Now().Before(end) {
+						MustBeSuccessful(lock.Lock(ctx))
+						w[i]++
+						time.Sleep(2 * time.Millisecond)
+						lock.Unlock()
+					}
+					wg.Done()
+				}()
+			}
+			MustBeSuccessful(wg.Wait(ctx))
+			return r, w
+		}
+
+		// waitingWriter starts a Go routine waiting for the write lock.
+		waitingWriter := func(ctx context.Context, lock syncutils.RWMutex) *syncutils.WaitGroup {
+			wg := &syncutils.WaitGroup{}
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				MustBeSuccessful(lock.Lock(ctx))
+				lock.Unlock()
+				wg.Done()
+			}()
+			time.Sleep(100 * time.Millisecond)
+			return wg
+		}
+
+		Context("reader preferring", func() {
+			BeforeEach(func() {
+				lock = syncutils.NewRWMutex(syncutils.READER_PREFERRING)
+			})
+
+			It("admits readers with waiting writer", func(ctx SpecContext) {
+				MustBeSuccessful(lock.RLock(ctx))
+				wg := waitingWriter(ctx, lock)
+				Expect(lock.TryRLock()).To(BeTrue())
+				lock.RUnlock()
+				lock.RUnlock()
+				MustBeSuccessful(wg.Wait(ctx))
+			}, SpecTimeout(2*time.Second))
+
+			It("starves writers with overlapping readers", func(ctx SpecContext) {
+				MustBeSuccessful(lock.RLock(ctx))
+				var locked atomic.Bool
+				wg := &syncutils.WaitGroup{}
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					MustBeSuccessful(lock.Lock(ctx))
+					locked.Store(true)
+					lock.Unlock()
+					wg.Done()
+				}()
+				for i := 0; i < 5; i++ {
+					time.Sleep(50 * time.Millisecond)
+					Expect(lock.TryRLock()).To(BeTrue())
+					lock.RUnlock()
+					Expect(locked.Load()).To(BeFalse())
+				}
+				lock.RUnlock()
+				MustBeSuccessful(wg.Wait(ctx))
+				Expect(locked.Load()).To(BeTrue())
+			}, SpecTimeout(2*time.Second))
+		})
+
+		Context("writer preferring", func() {
+			BeforeEach(func() {
+				lock = syncutils.NewRWMutex(syncutils.WRITER_PREFERRING)
+			})
+
+			It("blocks readers with waiting writer", func(ctx SpecContext) {
+				MustBeSuccessful(lock.RLock(ctx))
+				wg := waitingWriter(ctx, lock)
+				Expect(lock.TryRLock()).To(BeFalse())
+				lock.RUnlock()
+				MustBeSuccessful(wg.Wait(ctx))
+				Expect(lock.TryRLock()).To(BeTrue())
+			}, SpecTimeout(2*time.Second))
+
+			It("releases readers after cancelled writer", func(ctx SpecContext) {
+				MustBeSuccessful(lock.RLock(ctx))
+				cctx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+				defer cancel()
+				ExpectError(lock.Lock(cctx)).To(MatchError(context.DeadlineExceeded))
+				Expect(lock.TryRLock()).To(BeTrue())
+			}, SpecTimeout(2*time.Second))
+
+			It("does not starve writers", func(ctx SpecContext) {
+				_, w := stress(ctx, lock, 4, 2, 500*time.Millisecond)
+				Expect(w).NotTo(ContainElement(0))
+			}, SpecTimeout(5*time.Second))
+		})
+
+		Context("fair", func() {
+			BeforeEach(func() {
+				lock = syncutils.NewRWMutex(syncutils.FAIR)
+			})
+
+			It("grants in request order", func(ctx SpecContext) {
+				var order []string
+				MustBeSuccessful(lock.RLock(ctx))
+
+				wg := syncutils.WaitGroup{}
+				wg.Add(3)
+				start := func(name string, write bool) {
+					go func() {
+						defer GinkgoRecover()
+						if write {
+							MustBeSuccessful(lock.Lock(ctx))
+							order = append(order, name)
+							lock.Unlock()
+						} else {
+							MustBeSuccessful(lock.RLock(ctx))
+							order = append(order, name)
+							time.Sleep(50 * time.Millisecond)
+							lock.RUnlock()
+						}
+						wg.Done()
+					}()
+					time.Sleep(50 * time.Millisecond)
+				}
+				start("writer", true)
+				start("reader", false)
+				Expect(lock.TryRLock()).To(BeFalse())
+				start("last", true)
+				lock.RUnlock()
+				MustBeSuccessful(wg.Wait(ctx))
+				Expect(order).To(Equal([]string{"writer", "reader", "last"}))
+			}, SpecTimeout(2*time.Second))
+
+			It("grants waiting readers together", func(ctx SpecContext) {
+				MustBeSuccessful(lock.Lock(ctx))
+
+				wg := syncutils.WaitGroup{}
+				wg.Add(3)
+				for i := 0; i < 3; i++ {
+					go func() {
+						defer GinkgoRecover()
+						MustBeSuccessful(lock.RLock(ctx))
+						time.Sleep(300 * time.Millisecond)
+						lock.RUnlock()
+						wg.Done()
+					}()
+				}
+				time.Sleep(50 * time.Millisecond)
+				now := time.Now()
+				lock.Unlock()
+				MustBeSuccessful(wg.Wait(ctx))
+				Expect(time.Now().Sub(now)).To(BeNumerically("<", 600*time.Millisecond))
+			}, SpecTimeout(2*time.Second))
+
+			It("starves no one", func(ctx SpecContext) {
+				r, w := stress(ctx, lock, 4, 2, 500*time.Millisecond)
+				Expect(r).NotTo(ContainElement(0))
+				Expect(w).NotTo(ContainElement(0))
+			}, SpecTimeout(5*time.Second))
+		})
+	})
 })
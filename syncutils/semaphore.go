@@ -0,0 +1,163 @@
+package syncutils
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/mandelsoft/jobscheduler/syncutils/utils"
+)
+
+// Semaphore is a weighted counting semaphore.
+// As Locker it acquires and releases a weight of 1.
+type Semaphore interface {
+	Locker
+
+	// Acquire acquires the semaphore with a weight of w,
+	// blocking until the weight is available or the
+	// context is cancelled. A weight less than 1 or exceeding
+	// the size of the semaphore is rejected with an error.
+	Acquire(ctx context.Context, w int) error
+
+	// TryAcquire acquires the semaphore with a weight of w
+	// without blocking. It returns false if the weight
+	// is not available. It panics for a weight less than 1 or
+	// exceeding the size of the semaphore.
+	TryAcquire(w int) bool
+
+	// Release releases the semaphore with a weight of w.
+	// Only waiting Go routines, whose weight is available
+	// again, are deblocked. It panics for a weight less than 1 or
+	// exceeding the size of the semaphore.
+	Release(w int)
+
+	// Size returns the total weight of the semaphore.
+	Size() int
+	// Available returns the actually available weight.
+	Available() int
+}
+
+// semaphoreWaiter is a Go routine waiting for a weight.
+// It uses its own waiting list to be deblocked only
+// if its weight is available.
+type semaphoreWaiter struct {
+	weight  int
+	waiting utils.Waiting
+}
+
+type semaphore struct {
+	lock    sync.Mutex
+	size    int
+	cur     int
+	handler []utils.WaitingHandler
+	waiters []*semaphoreWaiter
+}
+
+// NewSemaphore creates a new Semaphore with a total weight of n.
+func NewSemaphore(n int, h ...utils.WaitingHandler) Semaphore {
+	return &semaphore{size: n, handler: h}
+}
+
+func (s *semaphore) Lock(ctx context.Context) error {
+	return s.Acquire(ctx, 1)
+}
+
+func (s *semaphore) Unlock() {
+	s.Release(1)
+}
+
+// check validates the weight requested for an operation.
+func (s *semaphore) check(w int) error {
+	if w <= 0 {
+		return fmt.Errorf("invalid semaphore weight %d", w)
+	}
+	if w > s.size {
+		return fmt.Errorf("weight %d exceeds semaphore size %d", w, s.size)
+	}
+	return nil
+}
+
+func (s *semaphore) Acquire(ctx context.Context, w int) error {
+	if err := s.check(w); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cur+w > s.size {
+		r := &semaphoreWaiter{weight: w, waiting: utils.NewWaiting(s.handler...)}
+		s.waiters = append(s.waiters, r)
+		defer s.dequeue(r)
+
+		for s.cur+w > s.size {
+			err := r.waiting.Wait(ctx, &s.lock)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	s.cur += w
+	return nil
+}
+
+// dequeue removes a satisfied or cancelled waiter.
+// A cancelled waiter might have been deblocked for
+// the available weight, so the next waiters are deblocked.
+func (s *semaphore) dequeue(r *semaphoreWaiter) {
+	s.waiters = slices.DeleteFunc(s.waiters, func(e *semaphoreWaiter) bool { return e == r })
+	s.signal()
+}
+
+// signal deblocks the waiters in request order, whose weight
+// fits into the available weight.
+func (s *semaphore) signal() {
+	avail := s.size - s.cur
+	for _, r := range s.waiters {
+		if r.weight <= avail {
+			r.waiting.SignalNext()
+			avail -= r.weight
+		}
+	}
+}
+
+func (s *semaphore) TryAcquire(w int) bool {
+	if err := s.check(w); err != nil {
+		panic(err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cur+w > s.size {
+		return false
+	}
+	s.cur += w
+	return true
+}
+
+func (s *semaphore) Release(w int) {
+	if err := s.check(w); err != nil {
+		panic(err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.cur-w < 0 {
+		panic("semaphore released more than held")
+	}
+	s.cur -= w
+	s.signal()
+}
+
+func (s *semaphore) Size() int {
+	return s.size
+}
+
+func (s *semaphore) Available() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.size - s.cur
+}
@@ -0,0 +1,110 @@
+package syncutils_test
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	. "github.com/mandelsoft/goutils/testutils"
+	"github.com/mandelsoft/jobscheduler/syncutils"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("semaphore Test Environment", func() {
+	var sem syncutils.Semaphore
+
+	BeforeEach(func() {
+		sem = syncutils.NewSemaphore(3)
+	})
+
+	It("acquires weights", func(ctx SpecContext) {
+		MustBeSuccessful(sem.Acquire(ctx, 2))
+		Expect(sem.Available()).To(Equal(1))
+		Expect(sem.TryAcquire(2)).To(BeFalse())
+		Expect(sem.TryAcquire(1)).To(BeTrue())
+		Expect(sem.Available()).To(Equal(0))
+		sem.Release(3)
+		Expect(sem.Available()).To(Equal(3))
+	}, SpecTimeout(time.Second))
+
+	It("rejects too large weight", func(ctx SpecContext) {
+		Expect(sem.Acquire(ctx, 4)).To(MatchError("weight 4 exceeds semaphore size 3"))
+		Expect(func() { sem.TryAcquire(4) }).To(PanicWith(MatchError("weight 4 exceeds semaphore size 3")))
+		Expect(func() { sem.Release(4) }).To(PanicWith(MatchError("weight 4 exceeds semaphore size 3")))
+	}, SpecTimeout(time.Second))
+
+	It("rejects non-positive weight", func(ctx SpecContext) {
+		Expect(sem.Acquire(ctx, 0)).To(MatchError("invalid semaphore weight 0"))
+		Expect(sem.Acquire(ctx, -1)).To(MatchError("invalid semaphore weight -1"))
+		Expect(func() { sem.TryAcquire(0) }).To(PanicWith(MatchError("invalid semaphore weight 0")))
+		Expect(func() { sem.Release(-1) }).To(PanicWith(MatchError("invalid semaphore weight -1")))
+		Expect(sem.Available()).To(Equal(3))
+	}, SpecTimeout(time.Second))
+
+	It("deblocks only waiters with available weight", func(ctx SpecContext) {
+		MustBeSuccessful(sem.Acquire(ctx, 3))
+
+		var large, small atomic.Bool
+		wg := &syncutils.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer GinkgoRecover()
+			MustBeSuccessful(sem.Acquire(ctx, 3))
+			large.Store(true)
+			wg.Done()
+		}()
+		time.Sleep(50 * time.Millisecond)
+		go func() {
+			defer GinkgoRecover()
+			MustBeSuccessful(sem.Acquire(ctx, 1))
+			small.Store(true)
+			wg.Done()
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		sem.Release(1)
+		Eventually(small.Load).Should(BeTrue())
+		Expect(large.Load()).To(BeFalse())
+		sem.Release(1)
+		sem.Release(1)
+		Consistently(large.Load, 100*time.Millisecond).Should(BeFalse())
+		sem.Release(1)
+		MustBeSuccessful(wg.Wait(ctx))
+		Expect(large.Load()).To(BeTrue())
+		Expect(sem.Available()).To(Equal(0))
+	}, SpecTimeout(2*time.Second))
+
+	It("timeout", func() {
+		MustBeSuccessful(sem.Acquire(nil, 2))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		ExpectError(sem.Acquire(ctx, 2)).To(MatchError(context.DeadlineExceeded))
+		Expect(sem.Available()).To(Equal(1))
+	})
+
+	It("waits for weight", func(ctx SpecContext) {
+		MustBeSuccessful(sem.Acquire(ctx, 3))
+
+		now := time.Now()
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			sem.Release(1)
+			time.Sleep(100 * time.Millisecond)
+			sem.Release(1)
+		}()
+		MustBeSuccessful(sem.Acquire(ctx, 2))
+		Expect(time.Now().Sub(now)).To(BeNumerically(">", 200*time.Millisecond))
+		Expect(sem.Available()).To(Equal(0))
+	}, SpecTimeout(time.Second))
+
+	It("locks", func(ctx SpecContext) {
+		for i := 0; i < 3; i++ {
+			MustBeSuccessful(sem.Lock(ctx))
+		}
+		Expect(sem.TryAcquire(1)).To(BeFalse())
+		sem.Unlock()
+		Expect(sem.TryAcquire(1)).To(BeTrue())
+	}, SpecTimeout(time.Second))
+})
@@ -15,6 +15,14 @@ func (l *List[E]) IsEmpty() bool {
 	return l.root == nil
 }
 
+func (l *List[E]) Len() int {
+	n := 0
+	for p := l.root; p != nil; p = p.next {
+		n++
+	}
+	return n
+}
+
 func (l *List[E]) Last() E {
 	var _nil E
 	if l.root == nil {
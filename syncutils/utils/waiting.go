@@ -2,6 +2,7 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/mandelsoft/goutils/general"
@@ -18,13 +19,19 @@ func newBlocker() *blocker {
 	return &blocker{c: make(chan struct{}, 1)}
 }
 
+// String identifies the blocker in log messages without
+// reading its fields concurrently to other Go routines.
+func (b *blocker) String() string {
+	return fmt.Sprintf("%p", b)
+}
+
 func (b *blocker) Wait(ctx context.Context) (bool, error) {
 	if ctx != nil {
 		select {
 		case <-ctx.Done():
-			log.Debug("waiting: cancelled", "locked", b.locked, "p", b)
 			// coordinate cancel and deblock
 			if b.Signal(false) {
+				log.Debug("waiting: cancelled", "p", b)
 				return false, ctx.Err()
 			}
 		case <-b.c:
@@ -67,16 +74,20 @@ type Block struct {
 
 func (b *Block) Wait(ctx context.Context) (bool, error) {
 	locked, err := b.blocker.Wait(ctx)
+	return locked, b.done(err)
+}
 
-	// remove entry.
-	// if it is still there, there was no Unblock call for this
-	// entry and a Block error has to be returned.
-	// if the entry is already gone, there was an Unblock and a potential
-	// interfering timeout can be ignored.
+// done removes the entry from the waiting list.
+// If it is still there, there was no Unblock call for this
+// entry and a Block error has to be returned.
+// If the entry is already gone, there was an Unblock and a potential
+// interfering timeout can be ignored.
+// It must be called under the lock of the synchronized data structure.
+func (b *Block) done(err error) error {
 	if b.waiting.waiting.Remove(matcher.Equals(b.blocker)) {
-		return locked, err
+		return err
 	}
-	return locked, nil
+	return nil
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -141,18 +152,28 @@ func (w *Waiting) Wait(ctx context.Context, opt ...sync.Locker) error {
 	if w.handler != nil {
 		w.handler.Release(ctx)
 	}
-	locked, err := b.Wait(ctx)
+	locked, err := b.blocker.Wait(ctx)
+	if !locked && l != nil {
+		l.Lock()
+	}
+	// the waiting list is shared with other Go routines,
+	// so the entry must be removed under the lock. Without
+	// a given lock the caller is responsible to synchronize
+	// the access. It is removed before allocating the resources
+	// to avoid signalling a cancelled entry in the meantime.
+	err = b.done(err)
 	log.Debug("waiting: wait done", "locked", locked, "error", err)
-	if !locked {
-		if w.handler != nil {
-			err2 := w.handler.Alloc(ctx)
-			if err == nil {
-				err = err2
-			}
+	if !locked && w.handler != nil {
+		if l != nil {
+			l.Unlock()
 		}
+		err2 := w.handler.Alloc(ctx)
 		if l != nil {
 			l.Lock()
 		}
+		if err == nil {
+			err = err2
+		}
 	}
 	return err
 }
@@ -183,6 +204,17 @@ func (w *Waiting) Signal(ctx context.Context, opt ...sync.Locker) bool {
 	return done
 }
 
+// SignalNext deblocks the first waiting go routine
+// without transferring a lock. It returns false if no one is found.
+func (w *Waiting) SignalNext() bool {
+	for !w.waiting.IsEmpty() {
+		if w.waiting.RemoveLast().Signal(false) {
+			return true
+		}
+	}
+	return false
+}
+
 // SignalAll unblocks all waiting
 // Go routines.
 func (w *Waiting) SignalAll() bool {